@@ -0,0 +1,377 @@
+package gowoocommerce
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// maxBatchSize is the maximum number of objects WooCommerce accepts in a single batch request
+const maxBatchSize = 100
+
+// WooBatchItemError is the error object WooCommerce returns per failed entry of a batch request
+// e.g.: {"code": "woocommerce_rest_product_invalid_id", "message": "Invalid ID.", "data": {"status": 400}}
+type WooBatchItemError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		Status     int   `json:"status,omitempty"`
+		ResourceID int32 `json:"resource_id,omitempty"` // set if the entity already exists, e.g. for "term_exists"
+	} `json:"data,omitempty"`
+}
+
+// Error implements the error interface
+func (e *WooBatchItemError) Error() string {
+	return fmt.Sprintf("%s: %s (%d)", e.Code, e.Message, e.Data.Status)
+}
+
+// WooBatchItemResult maps one input item of a batch request to the returned entity or its error
+type WooBatchItemResult struct {
+	Index    int                // position of the item in the slice passed to the batch operation
+	ID       int32              // ID of the created/updated/deleted entity; for failed creates usually 0
+	SKU      string             // SKU of the product, empty for non-product items
+	Input    WooItem            // the item as it was sent, nil for deletions
+	Response json.RawMessage    // the raw entity as returned by WooCommerce
+	Error    *WooBatchItemError // nil if the item succeeded
+}
+
+// WooBatchResponse merges the responses of all chunks of a batch operation
+// the entries are in the same order as the items passed to BatchCreate/BatchUpdate/BatchDelete
+type WooBatchResponse struct {
+	Create []WooBatchItemResult
+	Update []WooBatchItemResult
+	Delete []WooBatchItemResult
+}
+
+// Failed returns all item results that carry an error
+func (r WooBatchResponse) Failed() []WooBatchItemResult {
+	var failed []WooBatchItemResult
+	for _, results := range [][]WooBatchItemResult{r.Create, r.Update, r.Delete} {
+		for i := range results {
+			if results[i].Error != nil {
+				failed = append(failed, results[i])
+			}
+		}
+	}
+	return failed
+}
+
+// ParseBatchResponse maps the raw response of a WooBatchPostRequest to the items of said request
+// WooCommerce answers batch requests with HTTP 200 even if single items fail, the failures are reported per item
+func ParseBatchResponse(req WooBatchPostRequest, raw []byte) (WooBatchResponse, error) {
+	var parsed WooBatchResponse
+
+	var rsp struct {
+		Create []json.RawMessage `json:"create"`
+		Update []json.RawMessage `json:"update"`
+		Delete []json.RawMessage `json:"delete"`
+	}
+	err := json.Unmarshal(raw, &rsp)
+	if err != nil {
+		return parsed, err
+	}
+
+	parsed.Create, err = parseBatchItems(rsp.Create, req.Create, nil)
+	if err != nil {
+		return parsed, err
+	}
+	parsed.Update, err = parseBatchItems(rsp.Update, req.Update, nil)
+	if err != nil {
+		return parsed, err
+	}
+	parsed.Delete, err = parseBatchItems(rsp.Delete, nil, req.Delete)
+	if err != nil {
+		return parsed, err
+	}
+
+	return parsed, nil
+}
+
+// parseBatchItems parses the entries of one of the create/update/delete arrays of a batch response
+func parseBatchItems(entries []json.RawMessage, items []WooItem, ids []int) ([]WooBatchItemResult, error) {
+	results := make([]WooBatchItemResult, len(entries))
+
+	for i := range entries {
+		var entity struct {
+			ID    int32              `json:"id"`
+			SKU   string             `json:"sku"`
+			Error *WooBatchItemError `json:"error"`
+		}
+		err := json.Unmarshal(entries[i], &entity)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to parse batch item %d - %v", i, err)
+		}
+
+		results[i] = WooBatchItemResult{
+			Index:    i,
+			ID:       entity.ID,
+			SKU:      entity.SKU,
+			Response: entries[i],
+			Error:    entity.Error,
+		}
+
+		if i < len(items) {
+			results[i].Input = items[i]
+			if results[i].ID == 0 {
+				results[i].ID = items[i].GetID()
+			}
+			if results[i].SKU == "" {
+				results[i].SKU = itemSKU(items[i])
+			}
+		}
+		if i < len(ids) && results[i].ID == 0 {
+			results[i].ID = int32(ids[i])
+		}
+	}
+
+	return results, nil
+}
+
+// SetRequeueFailed defines whether items failing inside a batch are sent again (up to maxRetries times)
+func (w *WooConnection) SetRequeueFailed(requeue bool) {
+	w.requeueFailed.Store(requeue)
+}
+
+// SetBatchQueue makes BatchCreate, BatchUpdate, BatchDelete (and so SyncProducts) send their chunks through q,
+// e.g. a journaled queue: after a crash open the journal again, Execute the requests it restored and start
+// the import again (SyncProducts then updates what was already created). Chunks that failed are reported per
+// item and removed from q. Batch operations through q run one at a time, nil uses a new queue per operation.
+func (w *WooConnection) SetBatchQueue(q *WooRequestQueue) {
+	w.queueMu.Lock()
+	defer w.queueMu.Unlock()
+
+	w.batchQueue = q
+}
+
+// BatchCreate creates any number of items on a batch endpoint, e.g. "/wp-json/wc/v3/products/batch"
+// the items are split into chunks of productsPerBatch (see Init) which are sent concurrently
+func (w *WooConnection) BatchCreate(endpoint string, items []WooItem, verbose bool) (WooBatchResponse, error) {
+	return w.executeBatch(endpoint, items, nil, nil, verbose)
+}
+
+// BatchUpdate updates any number of items on a batch endpoint, every item must have an ID
+// the items are split into chunks of productsPerBatch (see Init) which are sent concurrently
+func (w *WooConnection) BatchUpdate(endpoint string, items []WooItem, verbose bool) (WooBatchResponse, error) {
+	return w.executeBatch(endpoint, nil, items, nil, verbose)
+}
+
+// BatchDelete deletes any number of items by their IDs on a batch endpoint
+// the IDs are split into chunks of productsPerBatch (see Init) which are sent concurrently
+func (w *WooConnection) BatchDelete(endpoint string, ids []int, verbose bool) (WooBatchResponse, error) {
+	return w.executeBatch(endpoint, nil, nil, ids, verbose)
+}
+
+// executeBatch sends the items, re-sends failed items if requested and reports partial failures as error
+func (w *WooConnection) executeBatch(endpoint string, create, update []WooItem, del []int, verbose bool) (WooBatchResponse, error) {
+	result, err := w.sendBatch(endpoint, create, update, del, verbose)
+	if err != nil {
+		return result, err
+	}
+
+	for attempt := 0; w.requeueFailed.Load() == true && attempt < w.maxRetries; attempt++ {
+		cIdx := failedIndices(result.Create)
+		uIdx := failedIndices(result.Update)
+		dIdx := failedIndices(result.Delete)
+		if len(cIdx)+len(uIdx)+len(dIdx) == 0 {
+			break
+		}
+
+		var retryCreate, retryUpdate []WooItem
+		var retryDelete []int
+		for _, idx := range cIdx {
+			retryCreate = append(retryCreate, create[idx])
+		}
+		for _, idx := range uIdx {
+			retryUpdate = append(retryUpdate, update[idx])
+		}
+		for _, idx := range dIdx {
+			retryDelete = append(retryDelete, del[idx])
+		}
+
+		retry, err := w.sendBatch(endpoint, retryCreate, retryUpdate, retryDelete, verbose)
+		if err != nil {
+			return result, err
+		}
+		mergeRetried(result.Create, retry.Create, cIdx)
+		mergeRetried(result.Update, retry.Update, uIdx)
+		mergeRetried(result.Delete, retry.Delete, dIdx)
+	}
+
+	failed := result.Failed()
+	if len(failed) > 0 {
+		total := len(result.Create) + len(result.Update) + len(result.Delete)
+		return result, fmt.Errorf("%d of %d batch items failed - first error: %v", len(failed), total, failed[0].Error)
+	}
+
+	return result, nil
+}
+
+// sendBatch queues the chunked requests, executes them and merges the parsed responses in order
+// the items of a chunk that got no response (e.g. HTTP 500) are reported with a gowoocommerce_batch_failed error
+func (w *WooConnection) sendBatch(endpoint string, create, update []WooItem, del []int, verbose bool) (WooBatchResponse, error) {
+	var merged WooBatchResponse
+
+	if w.initialized == false {
+		return merged, errors.New("Please initialize with your credentials first. WooConnection.Init()")
+	}
+
+	var requests []WooBatchPostRequest
+	size := w.batchSize()
+	for _, chunk := range chunkItems(create, size) {
+		requests = append(requests, WooBatchPostRequest{Endpoint: endpoint, Create: chunk})
+	}
+	for _, chunk := range chunkItems(update, size) {
+		requests = append(requests, WooBatchPostRequest{Endpoint: endpoint, Update: chunk})
+	}
+	for start := 0; start < len(del); start += size {
+		end := start + size
+		if end > len(del) {
+			end = len(del)
+		}
+		requests = append(requests, WooBatchPostRequest{Endpoint: endpoint, Delete: del[start:end]})
+	}
+
+	w.queueMu.Lock()
+	queue := w.batchQueue
+	w.queueMu.Unlock()
+	if queue == nil {
+		queue = w.NewRequestQueue()
+	} else {
+		w.batchMu.Lock()
+		defer w.batchMu.Unlock()
+		if n := queue.Len(); n > 0 {
+			return merged, fmt.Errorf("The batch queue still holds %d requests, execute them first", n)
+		}
+	}
+
+	for i := range requests {
+		err := queue.Push(requests[i])
+		if err != nil {
+			return merged, errors.Join(err, dropQueued(queue))
+		}
+	}
+
+	// non-strict: the chunks that succeeded are reported even if others fail
+	rawResponse, err := queue.Execute(false, verbose)
+	// failed chunks are reported below, a resumed run must not send them again
+	if dropErr := dropQueued(queue); err == nil {
+		err = dropErr
+	}
+
+	for i := range requests {
+		var r WooBatchResponse
+		if i < len(rawResponse) && rawResponse[i] != nil {
+			var parseErr error
+			r, parseErr = ParseBatchResponse(requests[i], rawResponse[i])
+			if parseErr != nil {
+				r = failedBatch(requests[i], fmt.Sprintf("Unable to parse batch response %d of %d - %v", i+1, len(requests), parseErr))
+			}
+		} else {
+			r = failedBatch(requests[i], fmt.Sprintf("Batch request %d of %d failed", i+1, len(requests)))
+		}
+		merged.Create = appendWithOffset(merged.Create, r.Create)
+		merged.Update = appendWithOffset(merged.Update, r.Update)
+		merged.Delete = appendWithOffset(merged.Delete, r.Delete)
+	}
+
+	return merged, err
+}
+
+// dropQueued removes everything left in the queue (and its journal)
+func dropQueued(queue *WooRequestQueue) error {
+	positions := make([]int, queue.Len())
+	for i := range positions {
+		positions[i] = i
+	}
+	_, err := queue.Remove(positions...)
+	return err
+}
+
+// failedBatch returns a result for every item of a batch request that got no usable response
+func failedBatch(req WooBatchPostRequest, message string) WooBatchResponse {
+	var failed WooBatchResponse
+	itemErr := &WooBatchItemError{Code: "gowoocommerce_batch_failed", Message: message}
+	for i, item := range req.Create {
+		failed.Create = append(failed.Create, WooBatchItemResult{Index: i, ID: item.GetID(), SKU: itemSKU(item), Input: item, Error: itemErr})
+	}
+	for i, item := range req.Update {
+		failed.Update = append(failed.Update, WooBatchItemResult{Index: i, ID: item.GetID(), SKU: itemSKU(item), Input: item, Error: itemErr})
+	}
+	for i, id := range req.Delete {
+		failed.Delete = append(failed.Delete, WooBatchItemResult{Index: i, ID: int32(id), Error: itemErr})
+	}
+	return failed
+}
+
+// appendWithOffset appends the results of one chunk and shifts their indices behind the existing ones
+func appendWithOffset(results, chunk []WooBatchItemResult) []WooBatchItemResult {
+	offset := len(results)
+	for i := range chunk {
+		chunk[i].Index += offset
+		results = append(results, chunk[i])
+	}
+	return results
+}
+
+// failedIndices returns the indices of all results that carry an error
+func failedIndices(results []WooBatchItemResult) []int {
+	var idx []int
+	for i := range results {
+		if results[i].Error != nil {
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+// mergeRetried replaces the results at the given indices by the results of the retry
+func mergeRetried(results, retried []WooBatchItemResult, indices []int) {
+	for j := range retried {
+		if j >= len(indices) {
+			break
+		}
+		retried[j].Index = indices[j]
+		results[indices[j]] = retried[j]
+	}
+}
+
+// batchSize returns the configured chunk size capped to what WooCommerce accepts
+func (w *WooConnection) batchSize() int {
+	if w.batchStrideSize < 1 || w.batchStrideSize > maxBatchSize {
+		return maxBatchSize
+	}
+	return w.batchStrideSize
+}
+
+// chunkItems splits items into consecutive chunks of at most size elements
+func chunkItems(items []WooItem, size int) [][]WooItem {
+	var chunks [][]WooItem
+	for start := 0; start < len(items); start += size {
+		end := start + size
+		if end > len(items) {
+			end = len(items)
+		}
+		chunks = append(chunks, items[start:end])
+	}
+	return chunks
+}
+
+// itemSKU returns the SKU of product items, empty for everything else
+func itemSKU(item WooItem) string {
+	switch p := item.(type) {
+	case WooProduct:
+		return p.SKU
+	case *WooProduct:
+		return p.SKU
+	case *WooProductPatch:
+		sku, _ := p.fields["sku"].(string)
+		return sku
+	case WooRawItem:
+		var raw struct {
+			SKU string `json:"sku"`
+		}
+		json.Unmarshal(p, &raw)
+		return raw.SKU
+	}
+	return ""
+}
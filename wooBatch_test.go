@@ -0,0 +1,172 @@
+package gowoocommerce
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseBatchResponse(t *testing.T) {
+	req := WooBatchPostRequest{
+		Create: []WooItem{WooProduct{SKU: "new-1"}, WooProduct{SKU: "new-2"}},
+		Update: []WooItem{WooProduct{ID: 7, SKU: "old"}},
+		Delete: []int{9, 10},
+	}
+	raw := []byte(`{
+		"create": [
+			{"id": 101, "sku": "new-1"},
+			{"id": 0, "error": {"code": "product_invalid_sku", "message": "Duplicate SKU.", "data": {"status": 400, "resource_id": 55}}}
+		],
+		"update": [{"id": 7}],
+		"delete": [{"id": 9}, {"id": 0, "error": {"code": "woocommerce_rest_product_invalid_id", "message": "Invalid ID.", "data": {"status": 400}}}]
+	}`)
+
+	rsp, err := ParseBatchResponse(req, raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		result WooBatchItemResult
+		index  int
+		id     int32
+		sku    string
+		failed bool
+	}{
+		{"created", rsp.Create[0], 0, 101, "new-1", false},
+		{"create failed", rsp.Create[1], 1, 0, "new-2", true},
+		{"updated", rsp.Update[0], 0, 7, "old", false},
+		{"deleted", rsp.Delete[0], 0, 9, "", false},
+		{"delete failed", rsp.Delete[1], 1, 10, "", true},
+	}
+	for _, tt := range tests {
+		r := tt.result
+		if r.Index != tt.index || r.ID != tt.id || r.SKU != tt.sku || (r.Error != nil) != tt.failed {
+			t.Errorf("%s: got %+v", tt.name, r)
+		}
+	}
+	if rsp.Create[1].Error.Data.ResourceID != 55 {
+		t.Errorf("resource_id = %d, want 55", rsp.Create[1].Error.Data.ResourceID)
+	}
+	if rsp.Create[1].Input == nil || rsp.Delete[0].Input != nil {
+		t.Error("creates and updates keep their input, deletions have none")
+	}
+	if n := len(rsp.Failed()); n != 2 {
+		t.Errorf("Failed() returned %d results, want 2", n)
+	}
+
+	if _, err := ParseBatchResponse(req, []byte(`{"create": [42]}`)); err == nil {
+		t.Error("an entry that is not an object must be an error")
+	}
+}
+
+func TestAppendWithOffset(t *testing.T) {
+	chunk := func(n int) []WooBatchItemResult {
+		results := make([]WooBatchItemResult, n)
+		for i := range results {
+			results[i].Index = i
+		}
+		return results
+	}
+
+	var merged []WooBatchItemResult
+	for _, n := range []int{3, 0, 2, 1} {
+		merged = appendWithOffset(merged, chunk(n))
+	}
+	if len(merged) != 6 {
+		t.Fatalf("merged %d results, want 6", len(merged))
+	}
+	for i := range merged {
+		if merged[i].Index != i {
+			t.Errorf("result %d has index %d", i, merged[i].Index)
+		}
+	}
+}
+
+func TestMergeRetried(t *testing.T) {
+	failed := &WooBatchItemError{Code: "x"}
+	results := []WooBatchItemResult{{Index: 0, ID: 1}, {Index: 1, Error: failed}, {Index: 2, ID: 3}, {Index: 3, Error: failed}}
+
+	indices := failedIndices(results)
+	if len(indices) != 2 || indices[0] != 1 || indices[1] != 3 {
+		t.Fatalf("failedIndices = %v, want [1 3]", indices)
+	}
+
+	mergeRetried(results, []WooBatchItemResult{{Index: 0, ID: 2}, {Index: 1, Error: failed}}, indices)
+	if results[1].ID != 2 || results[1].Index != 1 || results[1].Error != nil {
+		t.Errorf("retried result 1 = %+v", results[1])
+	}
+	if results[3].Index != 3 || results[3].Error == nil {
+		t.Errorf("retried result 3 = %+v", results[3])
+	}
+}
+
+func TestChunkItems(t *testing.T) {
+	items := make([]WooItem, 5)
+	tests := []struct {
+		size int
+		want []int
+	}{
+		{2, []int{2, 2, 1}},
+		{5, []int{5}},
+		{100, []int{5}},
+	}
+	for _, tt := range tests {
+		chunks := chunkItems(items, tt.size)
+		if len(chunks) != len(tt.want) {
+			t.Errorf("size %d: %d chunks, want %d", tt.size, len(chunks), len(tt.want))
+			continue
+		}
+		for i := range chunks {
+			if len(chunks[i]) != tt.want[i] {
+				t.Errorf("size %d: chunk %d has %d items, want %d", tt.size, i, len(chunks[i]), tt.want[i])
+			}
+		}
+	}
+}
+
+func TestBatchCreateChunkFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		var req struct {
+			Create []WooProduct `json:"create"`
+		}
+		json.Unmarshal(b, &req)
+		var created []string
+		for _, p := range req.Create {
+			if p.SKU == "c" {
+				rw.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			created = append(created, fmt.Sprintf(`{"id": %d, "sku": %q}`, 100+len(created), p.SKU))
+		}
+		fmt.Fprintf(rw, `{"create": [%s]}`, strings.Join(created, ","))
+	}))
+	defer srv.Close()
+
+	var w WooConnection
+	err := w.Init(srv.URL, "key", "secret", 2, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items := []WooItem{WooProduct{SKU: "a"}, WooProduct{SKU: "b"}, WooProduct{SKU: "c"}}
+	rsp, err := w.BatchCreate("/wp-json/wc/v3/products/batch", items, false)
+	if err == nil {
+		t.Error("a failed chunk must be reported as error")
+	}
+	if len(rsp.Create) != 3 {
+		t.Fatalf("got %d results, want 3", len(rsp.Create))
+	}
+	if rsp.Create[0].ID != 100 || rsp.Create[1].ID != 101 || rsp.Create[0].Error != nil || rsp.Create[1].Error != nil {
+		t.Errorf("the results of the successful chunk were lost: %+v", rsp.Create[:2])
+	}
+	if r := rsp.Create[2]; r.Error == nil || r.Index != 2 || r.SKU != "c" {
+		t.Errorf("the item of the failed chunk = %+v, want an error", r)
+	}
+}
@@ -0,0 +1,717 @@
+package gowoocommerce
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// WooCSVOptions configures reading and writing WooCommerce's native product CSV format
+type WooCSVOptions struct {
+	Categories    []WooCategory // the shop's category tree, used to write/resolve "Parent > Child" paths
+	WeightUnit    string        // unit in the "Weight (kg)" header, default "kg"
+	DimensionUnit string        // unit in the "Length (cm)" headers, default "cm"
+}
+
+// csvJSONPrefix marks extra columns holding fields the native format can not express, e.g. "JSON: permalink"
+// WooCommerce's importer ignores these columns, ReadProductsCSV restores the fields from them
+const csvJSONPrefix = "JSON: "
+
+// csvColumn maps one native column to a product field
+type csvColumn struct {
+	header string
+	get    func(p *WooProduct, c *csvContext) string
+	set    func(p *WooProduct, v string, c *csvContext) error
+}
+
+// csvContext holds the resolved options while reading or writing
+type csvContext struct {
+	opts       WooCSVOptions
+	categories map[int32]WooCategory
+	paths      map[string]WooCategory // "Parent > Child" -> category
+}
+
+var csvColumns = []csvColumn{
+	{"ID", func(p *WooProduct, c *csvContext) string { return formatUint(p.ID) },
+		func(p *WooProduct, v string, c *csvContext) error {
+			id, err := strconv.ParseUint(v, 10, 32)
+			p.ID = uint32(id)
+			return err
+		}},
+	{"Type", func(p *WooProduct, c *csvContext) string { return p.Type },
+		func(p *WooProduct, v string, c *csvContext) error { p.Type = v; return nil }},
+	{"SKU", func(p *WooProduct, c *csvContext) string { return p.SKU },
+		func(p *WooProduct, v string, c *csvContext) error { p.SKU = v; return nil }},
+	{"Name", func(p *WooProduct, c *csvContext) string { return p.Name },
+		func(p *WooProduct, v string, c *csvContext) error { p.Name = v; return nil }},
+	{"Published", func(p *WooProduct, c *csvContext) string {
+		switch p.Status {
+		case "publish":
+			return "1"
+		case "private":
+			return "-1"
+		case "":
+			return ""
+		}
+		return "0"
+	}, func(p *WooProduct, v string, c *csvContext) error {
+		switch v {
+		case "1":
+			p.Status = "publish"
+		case "-1":
+			p.Status = "private"
+		case "0":
+			p.Status = "draft"
+		default:
+			return fmt.Errorf("invalid value %q", v)
+		}
+		return nil
+	}},
+	{"Is featured?", func(p *WooProduct, c *csvContext) string { return formatCSVBool(p.Featured) },
+		func(p *WooProduct, v string, c *csvContext) (err error) { p.Featured, err = parseCSVBool(v); return }},
+	{"Visibility in catalog", func(p *WooProduct, c *csvContext) string { return p.CatalogVisibility },
+		func(p *WooProduct, v string, c *csvContext) error { p.CatalogVisibility = v; return nil }},
+	{"Short description", func(p *WooProduct, c *csvContext) string { return p.ShortDescription },
+		func(p *WooProduct, v string, c *csvContext) error { p.ShortDescription = v; return nil }},
+	{"Description", func(p *WooProduct, c *csvContext) string { return p.Description },
+		func(p *WooProduct, v string, c *csvContext) error { p.Description = v; return nil }},
+	{"Date sale price starts", func(p *WooProduct, c *csvContext) string { return formatCSVTime(p.DateOnSaleFrom) },
+		func(p *WooProduct, v string, c *csvContext) (err error) {
+			p.DateOnSaleFrom, err = ParseWooTime(v)
+			return
+		}},
+	{"Date sale price ends", func(p *WooProduct, c *csvContext) string { return formatCSVTime(p.DateOnSaleTo) },
+		func(p *WooProduct, v string, c *csvContext) (err error) {
+			p.DateOnSaleTo, err = ParseWooTime(v)
+			return
+		}},
+	{"Tax status", func(p *WooProduct, c *csvContext) string { return p.TaxStatus },
+		func(p *WooProduct, v string, c *csvContext) error { p.TaxStatus = v; return nil }},
+	{"Tax class", func(p *WooProduct, c *csvContext) string { return p.TaxClass },
+		func(p *WooProduct, v string, c *csvContext) error { p.TaxClass = v; return nil }},
+	{"In stock?", func(p *WooProduct, c *csvContext) string {
+		switch p.StockStatus {
+		case "instock":
+			return "1"
+		case "outofstock":
+			return "0"
+		case "onbackorder":
+			return "backorder"
+		}
+		return ""
+	}, func(p *WooProduct, v string, c *csvContext) error {
+		switch strings.ToLower(v) {
+		case "1", "yes", "true":
+			p.StockStatus = "instock"
+		case "0", "no", "false":
+			p.StockStatus = "outofstock"
+		case "backorder":
+			p.StockStatus = "onbackorder"
+		default:
+			return fmt.Errorf("invalid value %q", v)
+		}
+		return nil
+	}},
+	{"Stock", func(p *WooProduct, c *csvContext) string { return formatInt(p.StockQuantity) },
+		func(p *WooProduct, v string, c *csvContext) (err error) { p.StockQuantity, err = parseInt32(v); return }},
+	{"Sold individually?", func(p *WooProduct, c *csvContext) string { return formatCSVBool(p.SoldIndividually) },
+		func(p *WooProduct, v string, c *csvContext) (err error) {
+			p.SoldIndividually, err = parseCSVBool(v)
+			return
+		}},
+	{"Weight", func(p *WooProduct, c *csvContext) string { return p.Weight },
+		func(p *WooProduct, v string, c *csvContext) error { p.Weight = v; return nil }},
+	{"Length", func(p *WooProduct, c *csvContext) string { return p.Dimensions.Length },
+		func(p *WooProduct, v string, c *csvContext) error { p.Dimensions.Length = v; return nil }},
+	{"Width", func(p *WooProduct, c *csvContext) string { return p.Dimensions.Width },
+		func(p *WooProduct, v string, c *csvContext) error { p.Dimensions.Width = v; return nil }},
+	{"Height", func(p *WooProduct, c *csvContext) string { return p.Dimensions.Height },
+		func(p *WooProduct, v string, c *csvContext) error { p.Dimensions.Height = v; return nil }},
+	{"Allow customer reviews?", func(p *WooProduct, c *csvContext) string { return formatCSVBool(p.ReviewsAllowed) },
+		func(p *WooProduct, v string, c *csvContext) (err error) {
+			p.ReviewsAllowed, err = parseCSVBool(v)
+			return
+		}},
+	{"Sale price", func(p *WooProduct, c *csvContext) string { return string(p.SalePrice) },
+		func(p *WooProduct, v string, c *csvContext) error { p.SalePrice = WooPrice(v); return nil }},
+	{"Regular price", func(p *WooProduct, c *csvContext) string { return string(p.RegularPrice) },
+		func(p *WooProduct, v string, c *csvContext) error { p.RegularPrice = WooPrice(v); return nil }},
+	{"Categories", func(p *WooProduct, c *csvContext) string {
+		var paths []string
+		for _, cat := range p.Categories {
+			paths = append(paths, c.categoryPath(cat))
+		}
+		return joinCSVList(paths)
+	}, func(p *WooProduct, v string, c *csvContext) error {
+		for _, path := range splitCSVList(v) {
+			p.Categories = append(p.Categories, c.resolveCategory(path))
+		}
+		return nil
+	}},
+	{"Tags", func(p *WooProduct, c *csvContext) string {
+		var names []string
+		for _, tag := range p.Tags {
+			names = append(names, tag.Name)
+		}
+		return joinCSVList(names)
+	}, func(p *WooProduct, v string, c *csvContext) error {
+		for _, name := range splitCSVList(v) {
+			p.Tags = append(p.Tags, WooTag{Name: name})
+		}
+		return nil
+	}},
+	{"Images", func(p *WooProduct, c *csvContext) string {
+		var srcs []string
+		for _, img := range p.Images {
+			srcs = append(srcs, img.SRC)
+		}
+		return joinCSVList(srcs)
+	}, func(p *WooProduct, v string, c *csvContext) error {
+		for _, src := range splitCSVList(v) {
+			p.Images = append(p.Images, WooImage{SRC: src})
+		}
+		return nil
+	}},
+	{"Parent", func(p *WooProduct, c *csvContext) string {
+		if p.ParentID == 0 {
+			return ""
+		}
+		return "id:" + formatInt(p.ParentID)
+	}, func(p *WooProduct, v string, c *csvContext) error {
+		ids, err := parseCSVIDs(v)
+		if err == nil && len(ids) == 1 {
+			p.ParentID = ids[0]
+		}
+		return err
+	}},
+	{"Grouped products", func(p *WooProduct, c *csvContext) string { return formatCSVIDs(p.GroupedProducts) },
+		func(p *WooProduct, v string, c *csvContext) (err error) {
+			p.GroupedProducts, err = parseCSVIDs(v)
+			return
+		}},
+	{"Upsells", func(p *WooProduct, c *csvContext) string { return formatCSVIDs(p.UpsellIds) },
+		func(p *WooProduct, v string, c *csvContext) (err error) { p.UpsellIds, err = parseCSVIDs(v); return }},
+	{"Cross-sells", func(p *WooProduct, c *csvContext) string { return formatCSVIDs(p.CrossSellIds) },
+		func(p *WooProduct, v string, c *csvContext) (err error) { p.CrossSellIds, err = parseCSVIDs(v); return }},
+	{"External URL", func(p *WooProduct, c *csvContext) string { return p.ExternalURL },
+		func(p *WooProduct, v string, c *csvContext) error { p.ExternalURL = v; return nil }},
+	{"Button text", func(p *WooProduct, c *csvContext) string { return p.ButtonText },
+		func(p *WooProduct, v string, c *csvContext) error { p.ButtonText = v; return nil }},
+	{"Position", func(p *WooProduct, c *csvContext) string { return formatInt(p.MenuOrder) },
+		func(p *WooProduct, v string, c *csvContext) (err error) { p.MenuOrder, err = parseInt32(v); return }},
+}
+
+// csvAttributeHeader matches e.g. "Attribute 1 value(s)"
+var csvAttributeHeader = regexp.MustCompile(`^Attribute (\d+) (name|value\(s\)|visible|global|default)$`)
+
+// csvUnitHeader matches e.g. "Weight (kg)"
+var csvUnitHeader = regexp.MustCompile(`^(Weight|Length|Width|Height) \(.*\)$`)
+
+// WriteProductsCSV writes the products in WooCommerce's native product CSV format
+// fields the native format can not express are written to additional "JSON: <field>" columns,
+// so ReadProductsCSV returns exactly the same products
+func WriteProductsCSV(wr io.Writer, products []WooProduct, opts WooCSVOptions) error {
+	ctx := newCSVContext(opts)
+
+	numAttributes := 0
+	metaKeys := make(map[string]bool)
+	for i := range products {
+		if len(products[i].Attributes) > numAttributes {
+			numAttributes = len(products[i].Attributes)
+		}
+		for _, key := range products[i].MetaData.Keys() {
+			metaKeys[key] = true
+		}
+	}
+	sortedMeta := make([]string, 0, len(metaKeys))
+	for key := range metaKeys {
+		sortedMeta = append(sortedMeta, key)
+	}
+	sort.Strings(sortedMeta)
+
+	var header []string
+	for _, col := range csvColumns {
+		header = append(header, ctx.header(col.header))
+	}
+	for n := 1; n <= numAttributes; n++ {
+		for _, suffix := range []string{"name", "value(s)", "visible", "global", "default"} {
+			header = append(header, fmt.Sprintf("Attribute %d %s", n, suffix))
+		}
+	}
+	for _, key := range sortedMeta {
+		header = append(header, "Meta: "+key)
+	}
+	numNative := len(header)
+
+	// native cells first, then find out which fields did not survive
+	rows := make([][]string, len(products))
+	extras := make([]map[string]string, len(products))
+	extraKeys := make(map[string]bool)
+	for i := range products {
+		rows[i] = nativeCSVRow(&products[i], ctx, numAttributes, sortedMeta)
+
+		lossy, err := lostCSVFields(products[i], header, rows[i], ctx)
+		if err != nil {
+			return fmt.Errorf("Product %d (%s): %v", i, products[i].SKU, err)
+		}
+		extras[i] = lossy
+		for key := range lossy {
+			extraKeys[key] = true
+		}
+	}
+	sortedExtra := make([]string, 0, len(extraKeys))
+	for key := range extraKeys {
+		sortedExtra = append(sortedExtra, key)
+	}
+	sort.Strings(sortedExtra)
+	for _, key := range sortedExtra {
+		header = append(header, csvJSONPrefix+key)
+	}
+
+	out := csv.NewWriter(wr)
+	err := out.Write(header)
+	if err != nil {
+		return err
+	}
+	for i := range rows {
+		row := append(rows[i], make([]string, len(header)-numNative)...)
+		for j, key := range sortedExtra {
+			row[numNative+j] = extras[i][key]
+		}
+		err = out.Write(row)
+		if err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// ReadProductsCSV reads products from WooCommerce's native product CSV format (e.g. an export of the shop)
+// unknown columns are ignored
+func ReadProductsCSV(r io.Reader, opts WooCSVOptions) ([]WooProduct, error) {
+	ctx := newCSVContext(opts)
+
+	in := csv.NewReader(r)
+	in.FieldsPerRecord = -1
+
+	header, err := in.Read()
+	if err != nil {
+		return nil, err
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff") // WooCommerce exports start with a BOM
+	}
+
+	var products []WooProduct
+	for line := 2; ; line++ {
+		row, err := in.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return products, err
+		}
+		p, err := parseCSVRow(header, row, ctx)
+		if err != nil {
+			return products, fmt.Errorf("Line %d: %v", line, err)
+		}
+		products = append(products, p)
+	}
+
+	return products, nil
+}
+
+// nativeCSVRow returns the cells of all native columns
+func nativeCSVRow(p *WooProduct, ctx *csvContext, numAttributes int, metaKeys []string) []string {
+	var row []string
+	for _, col := range csvColumns {
+		row = append(row, col.get(p, ctx))
+	}
+
+	for n := 0; n < numAttributes; n++ {
+		if n >= len(p.Attributes) {
+			row = append(row, "", "", "", "", "")
+			continue
+		}
+		a := p.Attributes[n]
+		values := a.Options
+		if len(values) == 0 && a.Option != "" {
+			values = []string{a.Option}
+		}
+		global := "0"
+		if a.ID != 0 {
+			global = "1"
+		}
+		var def string
+		for _, d := range p.DefaultAttributes {
+			if d["name"] == a.Name {
+				def = fmt.Sprint(d["option"])
+			}
+		}
+		row = append(row, a.Name, joinCSVList(values), formatCSVBool(a.Visible), global, def)
+	}
+
+	for _, key := range metaKeys {
+		value, _ := p.MetaData.GetString(key)
+		row = append(row, value)
+	}
+
+	return row
+}
+
+// lostCSVFields parses the native row again and returns the JSON of every field that differs from the original
+func lostCSVFields(p WooProduct, header, row []string, ctx *csvContext) (map[string]string, error) {
+	parsed, err := parseCSVRow(header, row, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	want, err := productJSONFields(p)
+	if err != nil {
+		return nil, err
+	}
+	have, err := productJSONFields(parsed)
+	if err != nil {
+		return nil, err
+	}
+
+	lossy := make(map[string]string)
+	for key := range want {
+		if bytes.Equal(want[key], have[key]) == false {
+			lossy[key] = formatCSVJSON(want[key])
+		}
+	}
+	for key := range have {
+		if _, ok := want[key]; ok == false {
+			lossy[key] = "null"
+		}
+	}
+	return lossy, nil
+}
+
+// parseCSVRow turns a row into a product, "JSON: <field>" columns take precedence over the native ones
+func parseCSVRow(header, row []string, ctx *csvContext) (WooProduct, error) {
+	var p WooProduct
+
+	cells := make(map[string]string, len(header))
+	for i := range header {
+		if i < len(row) {
+			cells[ctx.normalizeHeader(header[i])] = row[i]
+		}
+	}
+
+	for _, col := range csvColumns {
+		v := cells[col.header]
+		if v == "" {
+			continue
+		}
+		err := col.set(&p, v, ctx)
+		if err != nil {
+			return p, fmt.Errorf("column %q: %v", col.header, err)
+		}
+	}
+
+	// attributes are numbered, gaps are skipped
+	attributes := make(map[int]*WooAttribute)
+	var numbers []int
+	for i := range header {
+		match := csvAttributeHeader.FindStringSubmatch(header[i])
+		if match == nil || i >= len(row) || row[i] == "" {
+			continue
+		}
+		n, _ := strconv.Atoi(match[1])
+		a, ok := attributes[n]
+		if ok == false {
+			a = &WooAttribute{}
+			attributes[n] = a
+			numbers = append(numbers, n)
+		}
+		switch match[2] {
+		case "name":
+			a.Name = row[i]
+		case "value(s)":
+			a.Options = splitCSVList(row[i])
+		case "visible":
+			a.Visible, _ = parseCSVBool(row[i])
+		case "default":
+			a.Option = row[i] // moved to the default attributes below
+		}
+	}
+	sort.Ints(numbers)
+	for _, n := range numbers {
+		a := attributes[n]
+		if a.Option != "" {
+			p.DefaultAttributes = append(p.DefaultAttributes, map[string]interface{}{"name": a.Name, "option": a.Option})
+			a.Option = ""
+		}
+		if a.Name != "" {
+			p.Attributes = append(p.Attributes, *a)
+		}
+	}
+
+	for i := range header {
+		if strings.HasPrefix(header[i], "Meta: ") == false || i >= len(row) || row[i] == "" {
+			continue
+		}
+		var value interface{} = row[i]
+		if strings.HasPrefix(row[i], "{") || strings.HasPrefix(row[i], "[") {
+			var decoded interface{}
+			if json.Unmarshal([]byte(row[i]), &decoded) == nil {
+				value = decoded
+			}
+		}
+		p.MetaData = append(p.MetaData, WooMetaEntry{
+			Key:   strings.TrimPrefix(header[i], "Meta: "),
+			Value: value,
+		})
+	}
+
+	// fields the native columns could not express
+	overrides := make(map[string]json.RawMessage)
+	for i := range header {
+		if strings.HasPrefix(header[i], csvJSONPrefix) == false || i >= len(row) || row[i] == "" {
+			continue
+		}
+		key := strings.TrimPrefix(header[i], csvJSONPrefix)
+		raw, err := parseCSVJSON(key, row[i])
+		if err != nil {
+			return p, fmt.Errorf("column %q: %v", header[i], err)
+		}
+		overrides[key] = raw
+	}
+	if len(overrides) == 0 {
+		return p, nil
+	}
+
+	fields, err := productJSONFields(p)
+	if err != nil {
+		return p, err
+	}
+	for key := range overrides {
+		fields[key] = overrides[key]
+	}
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return p, err
+	}
+	var restored WooProduct
+	err = json.Unmarshal(b, &restored)
+	return restored, err
+}
+
+// productJSONFields returns the product as JSON object with the raw value of every field
+func productJSONFields(p WooProduct) (map[string]json.RawMessage, error) {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	err = json.Unmarshal(b, &fields)
+	return fields, err
+}
+
+// formatCSVJSON writes plain strings without quotes to keep the sheet readable
+func formatCSVJSON(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil && json.Valid([]byte(s)) == false {
+		return s
+	}
+	return string(raw)
+}
+
+// parseCSVJSON reverses formatCSVJSON: the cell is taken as JSON if it fits the field, as string otherwise
+func parseCSVJSON(key, cell string) (json.RawMessage, error) {
+	var probe WooProduct
+	if json.Valid([]byte(cell)) {
+		err := json.Unmarshal([]byte(`{"`+key+`":`+cell+`}`), &probe)
+		if err == nil {
+			return json.RawMessage(cell), nil
+		}
+	}
+	quoted, _ := json.Marshal(cell)
+	err := json.Unmarshal([]byte(`{"`+key+`":`+string(quoted)+`}`), &probe)
+	if err != nil {
+		return nil, err
+	}
+	return quoted, nil
+}
+
+func newCSVContext(opts WooCSVOptions) *csvContext {
+	if opts.WeightUnit == "" {
+		opts.WeightUnit = "kg"
+	}
+	if opts.DimensionUnit == "" {
+		opts.DimensionUnit = "cm"
+	}
+	ctx := &csvContext{
+		opts:       opts,
+		categories: make(map[int32]WooCategory, len(opts.Categories)),
+		paths:      make(map[string]WooCategory, len(opts.Categories)),
+	}
+	for _, cat := range opts.Categories {
+		ctx.categories[cat.ID] = cat
+	}
+	for _, cat := range opts.Categories {
+		ctx.paths[ctx.categoryPath(cat)] = cat
+	}
+	return ctx
+}
+
+// header returns the header of a native column including the units
+func (c *csvContext) header(name string) string {
+	switch name {
+	case "Weight":
+		return fmt.Sprintf("Weight (%s)", c.opts.WeightUnit)
+	case "Length", "Width", "Height":
+		return fmt.Sprintf("%s (%s)", name, c.opts.DimensionUnit)
+	}
+	return name
+}
+
+// normalizeHeader strips the units, so files of shops with other units can be read
+func (c *csvContext) normalizeHeader(header string) string {
+	if match := csvUnitHeader.FindStringSubmatch(header); match != nil {
+		return match[1]
+	}
+	return header
+}
+
+// categoryPath returns "Parent > Child" if the category tree is known, the plain name otherwise
+func (c *csvContext) categoryPath(cat WooCategory) string {
+	full, ok := c.categories[cat.ID]
+	if ok == false {
+		return cat.Name
+	}
+	path := []string{full.Name}
+	seen := map[int32]bool{full.ID: true}
+	for full.Parent != 0 && seen[full.Parent] == false {
+		parent, ok := c.categories[full.Parent]
+		if ok == false {
+			break
+		}
+		seen[parent.ID] = true
+		path = append([]string{parent.Name}, path...)
+		full = parent
+	}
+	return strings.Join(path, " > ")
+}
+
+// resolveCategory finds the category of a "Parent > Child" path, only the name is set for unknown categories
+func (c *csvContext) resolveCategory(path string) WooCategory {
+	segments := strings.Split(path, ">")
+	for i := range segments {
+		segments[i] = strings.TrimSpace(segments[i])
+	}
+	if cat, ok := c.paths[strings.Join(segments, " > ")]; ok {
+		return WooCategory{ID: cat.ID, Name: cat.Name, Slug: cat.Slug}
+	}
+	return WooCategory{Name: segments[len(segments)-1]}
+}
+
+// joinCSVList joins list values with ", ", commas inside values are escaped as "\,"
+func joinCSVList(values []string) string {
+	escaped := make([]string, len(values))
+	for i := range values {
+		escaped[i] = strings.ReplaceAll(values[i], ",", `\,`)
+	}
+	return strings.Join(escaped, ", ")
+}
+
+// splitCSVList reverses joinCSVList
+func splitCSVList(s string) []string {
+	var values []string
+	var current strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && s[i+1] == ',' {
+			current.WriteByte(',')
+			i++
+			continue
+		}
+		if s[i] == ',' {
+			values = append(values, strings.TrimSpace(current.String()))
+			current.Reset()
+			continue
+		}
+		current.WriteByte(s[i])
+	}
+	if v := strings.TrimSpace(current.String()); v != "" || len(values) > 0 {
+		values = append(values, v)
+	}
+	return values
+}
+
+// formatCSVIDs writes product references the way WooCommerce exports them: "id:12, id:13"
+func formatCSVIDs(ids []int32) string {
+	refs := make([]string, len(ids))
+	for i := range ids {
+		refs[i] = "id:" + formatInt(ids[i])
+	}
+	return strings.Join(refs, ", ")
+}
+
+// parseCSVIDs reads "id:12, id:13"; references by SKU can not be resolved offline and are skipped
+func parseCSVIDs(s string) ([]int32, error) {
+	var ids []int32
+	for _, ref := range splitCSVList(s) {
+		if strings.HasPrefix(ref, "id:") == false {
+			continue
+		}
+		id, err := parseInt32(strings.TrimPrefix(ref, "id:"))
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// formatCSVTime writes dates the way the native exporter does, "" if not set
+func formatCSVTime(t WooTime) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Time.Format("2006-01-02 15:04:05")
+}
+
+func formatCSVBool(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+func parseCSVBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "1", "yes", "true":
+		return true, nil
+	case "0", "no", "false":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid boolean %q", s)
+}
+
+func formatInt(i int32) string {
+	if i == 0 {
+		return ""
+	}
+	return strconv.FormatInt(int64(i), 10)
+}
+
+func formatUint(i uint32) string {
+	if i == 0 {
+		return ""
+	}
+	return strconv.FormatUint(uint64(i), 10)
+}
+
+func parseInt32(s string) (int32, error) {
+	i, err := strconv.ParseInt(s, 10, 32)
+	return int32(i), err
+}
@@ -16,16 +16,19 @@ type WooCategoryLinks struct {
 
 // WooCategory convers objects relating to the WC Category tree
 type WooCategory struct {
-	ID          int32            `json:"id,omitempty"`
-	Name        string           `json:"name"`
-	Alt         string           `json:"alt,omitempty"`
-	Slug        string           `json:"slug,omitempty"`
-	Parent      int32            `json:"parent,omitempty"`
-	Description string           `json:"description,omitempty"`
-	Image       WooImage         `json:"image,omitempty"`
-	MenuOrder   int32            `json:"menu_order,omitempty"`
-	Count       int32            `json:"count,omitempty"`
-	Links       WooCategoryLinks `json:"_links,omitempty"` // read-only
+	ID            int32            `json:"id,omitempty"`
+	Name          string           `json:"name"`
+	Alt           string           `json:"alt,omitempty"`
+	Slug          string           `json:"slug,omitempty"`
+	Parent        int32            `json:"parent,omitempty"`
+	Description   string           `json:"description,omitempty"`
+	Image         WooImage         `json:"image,omitempty"`
+	MenuOrder     int32            `json:"menu_order,omitempty"`
+	Count         int32            `json:"count,omitempty"`
+	Links         WooCategoryLinks `json:"_links,omitempty"`         // read-only
+	Lang          string           `json:"lang,omitempty"`           // WPML only
+	TranslationOf int32            `json:"translation_of,omitempty"` // WPML: ID of the original when creating a translation
+	Translations  map[string]int32 `json:"translations,omitempty"`   // WPML: category IDs by language, read-only
 }
 
 // GetID implements WooItem
@@ -2,13 +2,11 @@ package gowoocommerce
 
 import (
 	"bytes"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/cookiejar"
-	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -16,8 +14,6 @@ import (
 	"golang.org/x/net/publicsuffix"
 )
 
-var loadPageSize = 100
-
 type wooCredentials struct {
 	domain string
 	key    string
@@ -25,14 +21,23 @@ type wooCredentials struct {
 }
 
 // WooConnection interfaces with the WooCommerce backend
+// after Init it can be shared by multiple goroutines, every goroutine should use its own WooRequestQueue
 type WooConnection struct {
 	initialized           bool
 	credentials           wooCredentials
 	jar                   *cookiejar.Jar
 	maxRetries            int
-	batchStrideSize       int // defines the size of one chunk for the batch upload
-	maxConcurrentRequests int // defines how many requests can be sent concurrently
-	requestQueue          []WooRequest
+	batchStrideSize       int         // defines the size of one chunk for the batch upload (capped to 100)
+	maxConcurrentRequests int         // defines how many requests can be sent concurrently
+	requeueFailed         atomic.Bool // re-send items that failed inside a batch request
+	preflight             atomic.Bool // validate queued requests before sending them
+	queueMu               sync.Mutex
+	requestQueue          *WooRequestQueue // default queue for PushToQueue/ExecuteRequestQueue
+	batchQueue            *WooRequestQueue // optional queue of the batch operations, see SetBatchQueue
+	batchMu               sync.Mutex       // one batch operation at a time on batchQueue
+	data                  wooDataCache     // cached responses of the /data endpoints
+	dryRun                wooDryRun        // captures mutating requests instead of sending them
+	decoding              wooDecoding      // lenient decoding setting and report
 }
 
 // Init takes in the credentials before dong any other operation
@@ -63,14 +68,29 @@ func (w *WooConnection) Init(domain, key, secret string, productsPerBatch, maxCo
 
 // Request sends a request: ("GET", "POST"), endpoint, body
 func (w *WooConnection) Request(method, endpoint string, body []byte) ([]byte, error) {
+	b, _, err := w.requestWithHeader(method, endpoint, body)
+	return b, err
+}
+
+// requestWithHeader sends a request like Request and additionally returns the response header
+func (w *WooConnection) requestWithHeader(method, endpoint string, body []byte) ([]byte, http.Header, error) {
+	return w.requestWithContentType(method, endpoint, "application/json", body)
+}
+
+// requestWithContentType sends a body of any type, e.g. the multipart uploads of UploadMedia
+func (w *WooConnection) requestWithContentType(method, endpoint, contentType string, body []byte) ([]byte, http.Header, error) {
+	if rsp, captured := w.captureDryRun(method, endpoint, body); captured == true {
+		return rsp, http.Header{}, nil
+	}
+
 	url := w.buildLink(endpoint)
 
 	req, err := http.NewRequest(method, url, bytes.NewReader(body))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	req.SetBasicAuth(w.credentials.key, w.credentials.secret)
-	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Type", contentType)
 
 	client := &http.Client{Jar: w.jar}
 	rsp, err := client.Do(req)
@@ -78,19 +98,20 @@ func (w *WooConnection) Request(method, endpoint string, body []byte) ([]byte, e
 		defer rsp.Body.Close()
 		b, err := ioutil.ReadAll(rsp.Body)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		if rsp.StatusCode != http.StatusOK && rsp.StatusCode != http.StatusCreated {
-			return nil, fmt.Errorf("Failed: %s: %s \n %s - %s", method, endpoint, rsp.Status, string(b))
+			return nil, nil, fmt.Errorf("Failed: %s: %s \n %s - %s", method, endpoint, rsp.Status, string(b))
 		}
-		return b, nil
+		return b, rsp.Header, nil
 	}
 
-	return nil, err
+	return nil, nil, err
 }
 
 // GetAllProducts returns all products from the WC backend
+// use Paginate to stream large catalogs instead of loading them into memory at once
 func (w *WooConnection) GetAllProducts(verbose bool) ([]WooProduct, error) {
 	var currentProducts []WooProduct
 
@@ -98,42 +119,13 @@ func (w *WooConnection) GetAllProducts(verbose bool) ([]WooProduct, error) {
 		return currentProducts, errors.New("Please initialize with your credentials first. WooConnection.Init()")
 	}
 
-	totalNumProducts, err := w.getNumItems("/wp-json/wc/v3/products?per_page=1") //get total number of items from product endpoint
-	if err != nil {
-		return currentProducts, err
-	}
-
-	if loadPageSize > totalNumProducts {
-		loadPageSize = totalNumProducts
-	}
-
-	for offset := 0; offset < totalNumProducts; offset += loadPageSize {
-		endpoint := "/wp-json/wc/v3/products?orderby=id&order=asc&offset=" + strconv.Itoa(offset)
-		endpoint += "&per_page=" + strconv.Itoa(loadPageSize)
-
-		w.PushToQueue(
-			WooGetRequest{
-				Endpoint: endpoint,
-			},
-		)
-	}
-	rawResponse, err := w.ExecuteRequestQueue(true, verbose)
-	if err != nil {
-		return currentProducts, err
-	}
-
-	//currentProducts = make([]WooProduct, 0)
-	for i := range rawResponse {
-		if len(rawResponse[i]) < 1 {
-			continue
-		}
-		var p []WooProduct
-		err = json.Unmarshal(rawResponse[i], &p)
+	for p, err := range Paginate[WooProduct](w, "/wp-json/wc/v3/products?orderby=id&order=asc", defaultPageSize) {
 		if err != nil {
-			continue
+			return currentProducts, err
 		}
-		for j := range p {
-			currentProducts = append(currentProducts, p[j])
+		currentProducts = append(currentProducts, p)
+		if verbose == true && len(currentProducts)%defaultPageSize == 0 {
+			fmt.Printf("%d products loaded\n", len(currentProducts))
 		}
 	}
 
@@ -141,230 +133,62 @@ func (w *WooConnection) GetAllProducts(verbose bool) ([]WooProduct, error) {
 }
 
 // PurgeProducts deletes all the products from the woo commerce backend
-// Remember: Does not remove the image assets from the server!
+// Remember: Does not remove the image assets from the server! Use PurgeProductsWith for filters, backups and media cleanup
 func (w *WooConnection) PurgeProducts(verbose bool) error {
-	if w.initialized == false {
-		return fmt.Errorf("Please initialize with your credentials first. WooConnection.Init()")
-	}
-
-	products, err := w.GetAllProducts(true)
-	if err != nil {
-		return err
-	}
-
-	endpoint := "/wp-json/wc/v3/products/batch"
-
-	var r = WooBatchPostRequest{
-		Endpoint: endpoint,
-	}
-
-	// Preparing queue of product ids to be deleted
-	for i := range products {
-		r.Delete = append(r.Delete, int(products[i].ID))
-
-		if i%16 == 0 || i >= len(products)-1 {
-			w.PushToQueue(r)
-			r = WooBatchPostRequest{
-				Endpoint: endpoint,
-			}
-		}
-	}
-
-	_, err = w.ExecuteRequestQueue(true, verbose)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	_, err := w.PurgeProductsWith(WooPurgeOptions{
+		All:     true,
+		Force:   true,
+		Verbose: verbose,
+	})
+	return err
 }
 
 // QueryCategories returns all categories from the WC backend
 // https://woocommerce.github.io/woocommerce-rest-api-docs/#list-all-product-categories
+//
+// Deprecated: searchString is appended unescaped, use GetCategories with a WooCategoryQuery instead
 func (w *WooConnection) QueryCategories(searchString string) ([]WooCategory, error) {
-	var categories []WooCategory
-	pageSize := 10
-
 	if w.initialized == false {
-		return categories, errors.New("Please initialize with your credentials first. WooConnection.Init()")
-	}
-
-	endpoint := "/wp-json/wc/v3/products/categories"
-
-	totalNumCats, err := w.getNumItems(fmt.Sprintf("%s?per_page=1%s", endpoint, searchString)) //get total number of items from category endpoint
-	if err != nil {
-		return categories, err
-	}
-
-	endpoint += fmt.Sprintf("?per_page=%d%s", pageSize, searchString)
-
-	var r WooGetRequest
-
-	nPage := 1
-	for i := 0; i < totalNumCats; i += pageSize {
-		r = WooGetRequest{
-			Endpoint: fmt.Sprintf("%s&page=%d", endpoint, nPage),
-		}
-		w.PushToQueue(r)
-		nPage++
-	}
-	rawResonse, err := w.ExecuteRequestQueue(true, false)
-	if err != nil {
-		return categories, err
+		return nil, errors.New("Please initialize with your credentials first. WooConnection.Init()")
 	}
 
-	categories = make([]WooCategory, totalNumCats)
-	for i := range rawResonse {
-		var c []WooCategory
-		err = json.Unmarshal(rawResonse[i], &c)
-		if err != nil {
-			return categories, err
-		}
-		for j := range c {
-			idx := (pageSize * i) + j
-			categories[idx] = c[j]
-		}
-	}
+	endpoint := "/wp-json/wc/v3/products/categories?orderby=id" + searchString
 
-	return categories, nil
+	return CollectAll(Paginate[WooCategory](w, endpoint, defaultPageSize))
 }
 
-// PushToQueue appends a WooREquest to the queue to later be executed
+// PushToQueue appends a WooREquest to the default queue of the connection to later be executed
+//
+// Deprecated: the default queue is shared by everyone using the connection, use NewRequestQueue instead
 func (w *WooConnection) PushToQueue(r WooRequest) {
-	w.requestQueue = append(w.requestQueue, r)
+	w.defaultQueue().Push(r)
 }
 
 // ExecuteRequestQueue executes all the request that were pushed before and returns an array of the raw responses as bytes
+// the responses are in the same order as the requests were pushed to the queue
 // if strict: returns on any error; else: finishes regardless of errors
+//
+// Deprecated: the default queue is shared by everyone using the connection, use NewRequestQueue instead
 func (w *WooConnection) ExecuteRequestQueue(strict, verbose bool) ([][]byte, error) {
-	var rawResponse [][]byte
-
-	if len(w.requestQueue) == 0 {
-		return rawResponse, nil
-	}
-	var wg sync.WaitGroup
-
-	input := make(chan WooRequest, len(w.requestQueue))
-	output := make(chan []byte, len(w.requestQueue))
-	errChan := make(chan error, len(w.requestQueue))
-
-	// Increment waitgroup counter and create go routines
-	for i := 0; i < w.maxConcurrentRequests; i++ {
-		wg.Add(1)
-		go func(input chan WooRequest, output chan []byte) {
-			defer wg.Done()
-
-			for req := range input {
-				resp, err := req.Send(w)
-				errChan <- err
-				output <- resp
-			}
-		}(input, output)
-	}
-
-	// Producer: load up input channel with jobs
-	for _, job := range w.requestQueue {
-		input <- job
-	}
-	fmt.Printf("%d scheduled \n", len(w.requestQueue))
-
-	close(input)
-
-	rawResponse = make([][]byte, len(w.requestQueue))
-	var i int
-	for res := range output {
-		rawResponse[i] = res
-		i++
-		if verbose == true {
-			progressBar(i, len(w.requestQueue))
-		}
-
-		if i >= len(w.requestQueue) {
-			close(output)
-			close(errChan)
-			break
-		}
-	}
-
-	for err := range errChan {
-		if err != nil {
-			fmt.Println(err)
-			if strict == true {
-				return rawResponse, err
-			}
-		}
-	}
-
-	wg.Wait()
-
-	w.requestQueue = nil
-
-	return rawResponse, nil
+	return w.defaultQueue().Execute(strict, verbose)
 }
 
 // ViewRequestQueue returns the marshalled requests as they will be sent by ExecuteRequestQueue
+//
+// Deprecated: the default queue is shared by everyone using the connection, use NewRequestQueue instead
 func (w *WooConnection) ViewRequestQueue() ([][]byte, error) {
-	output := make([][]byte, len(w.requestQueue))
-
-	var err error
-	var errorCounter uint32
-
-	var wg sync.WaitGroup
-	for i := range w.requestQueue {
-		wg.Add(1)
-		go func(it int) {
-			defer wg.Done()
-			output[it], err = json.Marshal(w.requestQueue[it])
-			if err != nil {
-				fmt.Println(err)
-				atomic.AddUint32(&errorCounter, 1)
-			}
-
-		}(i)
-		if i%8 == 0 {
-			wg.Wait()
-		}
-		if errorCounter > 0 {
-			err = fmt.Errorf("Encountered %d error in %d requests", errorCounter, len(w.requestQueue))
-			break
-		}
-	}
-	wg.Wait()
-
-	return output, err
+	return w.defaultQueue().View()
 }
 
-// getNumItems returns the total number of items (products, categories) from the repsonse header of a given endpoint
-func (w *WooConnection) getNumItems(endpoint string) (int, error) {
-	if w.initialized == false {
-		return 0, errors.New("Please initialize with your credentials first. WooConnection.Init()")
-	}
-
-	url := w.buildLink(endpoint)
+// defaultQueue returns the queue behind PushToQueue/ExecuteRequestQueue, created on first use
+func (w *WooConnection) defaultQueue() *WooRequestQueue {
+	w.queueMu.Lock()
+	defer w.queueMu.Unlock()
 
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return 0, err
+	if w.requestQueue == nil {
+		w.requestQueue = w.NewRequestQueue()
 	}
-	req.SetBasicAuth(w.credentials.key, w.credentials.secret)
-	req.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{Jar: w.jar}
-	rsp, err := client.Do(req)
-	if err != nil {
-		return 0, err
-	}
-
-	if rsp.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("Failed: %s\n %s", endpoint, rsp.Status)
-	}
-
-	numP := rsp.Header.Get("X-WP-Total")
-	np, err := strconv.ParseInt(numP, 0, 32)
-	if err != nil {
-		return 0, fmt.Errorf("Unable to gather total number of products - %v", err)
-	}
-
-	return int(np), nil
+	return w.requestQueue
 }
 
 func (w *WooConnection) buildLink(endpoint string) string {
@@ -0,0 +1,262 @@
+package gowoocommerce
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+const (
+	RoundNearest = ""     // round to the nearest step (default)
+	RoundUp      = "up"   // never below the converted price
+	RoundDown    = "down" // never above the converted price
+)
+
+// WooRoundingRule describes how converted prices are rounded in a currency
+// e.g. {Decimals: 2, Step: NewMoney(1, 0), Ending: NewMoney(1, 2)} gives .99 endings: 12.34 -> 11.99, 12.60 -> 12.99
+// and {Decimals: 0, Step: NewMoney(10, 0), Ending: NewMoney(1, 0)} gives prices like 1239 (JPY).
+type WooRoundingRule struct {
+	Decimals int      // decimals of the currency, e.g. 2 (0 for JPY)
+	Step     WooMoney // round to multiples of Step (before the ending is applied), zero rounds to Decimals
+	Ending   WooMoney // subtracted from the rounded price, e.g. 0.01 for .99 endings
+	Mode     string   // RoundNearest, RoundUp or RoundDown
+}
+
+// Apply rounds m according to the rule, positive prices never become zero or negative and zero stays zero
+func (r WooRoundingRule) Apply(m WooMoney) WooMoney {
+	if m.IsZero() {
+		return m
+	}
+	step := r.Step
+	if step.Sign() <= 0 {
+		step = NewMoney(1, r.Decimals)
+	}
+
+	rounded := roundToStep(m.Add(r.Ending), step, r.Mode).Sub(r.Ending)
+	if rounded.Sign() <= 0 && m.Sign() > 0 {
+		rounded = step.Sub(r.Ending)
+		if rounded.Sign() <= 0 {
+			rounded = m
+		}
+	}
+	return rounded.Round(r.Decimals)
+}
+
+// WooCurrencyPricing converts prices of the shop's base currency into other currencies
+// for WooCommerce Multilingual's multi-currency custom prices (WooProduct.CustomPrices)
+type WooCurrencyPricing struct {
+	Rates           map[string]WooMoney        // units of the currency per unit of the base currency, e.g. "USD": 1.08
+	Rounding        map[string]WooRoundingRule // rounding per currency, DefaultRounding if not set
+	DefaultRounding WooRoundingRule            // e.g. {Decimals: 2}
+}
+
+// Currencies returns the configured currency codes sorted alphabetically
+func (c WooCurrencyPricing) Currencies() []string {
+	codes := make([]string, 0, len(c.Rates))
+	for code := range c.Rates {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Convert returns the rounded price of base in the given currency
+func (c WooCurrencyPricing) Convert(base WooMoney, currency string) (WooMoney, error) {
+	rate, ok := c.Rates[currency]
+	if ok == false {
+		return WooMoney{}, fmt.Errorf("No exchange rate for %s", currency)
+	}
+	if rate.Sign() <= 0 {
+		return WooMoney{}, fmt.Errorf("Invalid exchange rate %s for %s", rate, currency)
+	}
+
+	rule, ok := c.Rounding[currency]
+	if ok == false {
+		rule = c.DefaultRounding
+	}
+	converted := rule.Apply(base.Mul(rate))
+	if converted.Overflowed() {
+		return WooMoney{}, fmt.Errorf("Price %s out of range in %s", base, currency)
+	}
+	return converted, nil
+}
+
+// CustomPrices converts a regular and an optional sale price into every configured currency
+// An unset regular price (e.g. of a variable product) returns nil. It is an error if a sale price
+// is not below the regular price after rounding, e.g. 12.50 and 12.40 both become 12.99 when rounding up to .99.
+func (c WooCurrencyPricing) CustomPrices(regular, sale WooPrice) (map[string]WpmlPrice, error) {
+	if regular.IsSet() == false {
+		return nil, nil
+	}
+	if len(c.Rates) == 0 {
+		return nil, errors.New("No exchange rates given")
+	}
+
+	regularBase, err := regular.Money()
+	if err != nil {
+		return nil, err
+	}
+	var saleBase WooMoney
+	if sale.IsSet() {
+		saleBase, err = sale.Money()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	prices := make(map[string]WpmlPrice, len(c.Rates))
+	for _, currency := range c.Currencies() {
+		rule, ok := c.Rounding[currency]
+		if ok == false {
+			rule = c.DefaultRounding
+		}
+
+		converted, err := c.Convert(regularBase, currency)
+		if err != nil {
+			return nil, err
+		}
+		price := WpmlPrice{RegularPrice: converted.Price(rule.Decimals)}
+
+		if sale.IsSet() {
+			convertedSale, err := c.Convert(saleBase, currency)
+			if err != nil {
+				return nil, err
+			}
+			if convertedSale.Cmp(converted) >= 0 {
+				return nil, fmt.Errorf("Sale price %s is not below regular price %s in %s", convertedSale.Format(rule.Decimals), converted.Format(rule.Decimals), currency)
+			}
+			price.SalePrice = convertedSale.Price(rule.Decimals)
+		}
+		prices[currency] = price
+	}
+	return prices, nil
+}
+
+// Apply sets the custom prices of p from its regular and sale price
+func (c WooCurrencyPricing) Apply(p *WooProduct) error {
+	prices, err := c.CustomPrices(p.RegularPrice, p.SalePrice)
+	if err != nil {
+		return err
+	}
+	p.CustomPrices = prices
+	return nil
+}
+
+// UpdateCurrencyPrices recalculates the custom prices of the products from their base prices
+// and sends them as batch updates. Variable products keep their prices in the variations: pass the
+// variations (type "variation" with ParentID, e.g. from Paginate on /products/<id>/variations) and
+// they are sent to the variations endpoint of their parent. Products without a regular price are
+// skipped; if any product can not be converted nothing is sent. A missing sale price is sent as ""
+// so a sale that ended in the base currency ends in every currency. Custom prices need WooCommerce
+// Multilingual with multi-currency enabled and are only used for products set to
+// "Set prices in other currencies manually". The Index of the update results is the position in products,
+// skipped products have no result.
+func (w *WooConnection) UpdateCurrencyPrices(products []WooProduct, pricing WooCurrencyPricing, verbose bool) (WooBatchResponse, error) {
+	var merged WooBatchResponse
+
+	if w.initialized == false {
+		return merged, errors.New("Please initialize with your credentials first. WooConnection.Init()")
+	}
+
+	var patches []*WooProductPatch
+	var positions []int // position in products of every patch
+	variations := make(map[int32][]*WooProductPatch)
+	variationPositions := make(map[int32][]int)
+	var parents []int32
+	var problems []string
+	for i := range products {
+		p := &products[i]
+		prices, err := pricing.CustomPrices(p.RegularPrice, p.SalePrice)
+		if err == nil && p.Type == "variation" && p.ParentID == 0 {
+			err = errors.New("variation without parent_id")
+		}
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%d (%s): %v", p.GetID(), p.SKU, err))
+			continue
+		}
+		if prices == nil {
+			if verbose == true {
+				fmt.Printf("skipping %d (%s), it has no regular price\n", p.GetID(), p.SKU)
+			}
+			continue
+		}
+
+		// WpmlPrice omits an empty sale price, here it has to be sent to end the sale
+		custom := make(map[string]map[string]WooPrice, len(prices))
+		for currency, price := range prices {
+			custom[currency] = map[string]WooPrice{"regular_price": price.RegularPrice, "sale_price": price.SalePrice}
+		}
+		patch := NewProductPatch(p.GetID()).Set("custom_prices", custom)
+		if p.Type == "variation" {
+			if _, ok := variations[p.ParentID]; ok == false {
+				parents = append(parents, p.ParentID)
+			}
+			variations[p.ParentID] = append(variations[p.ParentID], patch)
+			variationPositions[p.ParentID] = append(variationPositions[p.ParentID], i)
+			continue
+		}
+		patches = append(patches, patch)
+		positions = append(positions, i)
+	}
+	if len(problems) > 0 {
+		return merged, fmt.Errorf("Unable to convert the prices of %d products: %s", len(problems), strings.Join(problems, "; "))
+	}
+
+	var errs []error
+	if len(patches) > 0 {
+		rsp, err := w.PatchProducts(patches, verbose)
+		merged.Update = append(merged.Update, withPositions(rsp.Update, positions)...)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, parent := range parents {
+		rsp, err := w.PatchVariations(parent, variations[parent], verbose)
+		merged.Update = append(merged.Update, withPositions(rsp.Update, variationPositions[parent])...)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("variations of %d - %v", parent, err))
+		}
+	}
+	sort.SliceStable(merged.Update, func(i, j int) bool { return merged.Update[i].Index < merged.Update[j].Index })
+	if len(errs) > 0 {
+		return merged, fmt.Errorf("%d of %d batch updates failed, first: %v", len(errs), len(parents)+min(len(patches), 1), errs[0])
+	}
+
+	return merged, nil
+}
+
+// withPositions sets the Index of the results to the positions of their items in the caller's slice
+func withPositions(results []WooBatchItemResult, positions []int) []WooBatchItemResult {
+	for i := range results {
+		if results[i].Index < len(positions) {
+			results[i].Index = positions[results[i].Index]
+		}
+	}
+	return results
+}
+
+// roundToStep rounds m to a multiple of step
+func roundToStep(m, step WooMoney, mode string) WooMoney {
+	if m.Overflowed() {
+		return m
+	}
+	if step.Overflowed() {
+		return step
+	}
+	q, r := m.micros/step.micros, m.micros%step.micros
+	switch mode {
+	case RoundUp:
+		if r > 0 {
+			q++
+		}
+	case RoundDown:
+		if r < 0 {
+			q--
+		}
+	default:
+		q = roundDiv(big.NewInt(m.micros), big.NewInt(step.micros)).Int64()
+	}
+	return saturate(new(big.Int).Mul(big.NewInt(q), big.NewInt(step.micros)))
+}
@@ -0,0 +1,80 @@
+package gowoocommerce
+
+import "testing"
+
+func TestRoundingRuleApply(t *testing.T) {
+	ninetyNine := WooRoundingRule{Decimals: 2, Step: NewMoney(1, 0), Ending: NewMoney(1, 2)}
+	ninetyNineUp := ninetyNine
+	ninetyNineUp.Mode = RoundUp
+	ninetyNineDown := ninetyNine
+	ninetyNineDown.Mode = RoundDown
+	yen := WooRoundingRule{Step: NewMoney(10, 0), Ending: NewMoney(1, 0)}
+
+	tests := []struct {
+		name string
+		rule WooRoundingRule
+		in   string
+		want string
+	}{
+		{".99 nearest down", ninetyNine, "12.34", "11.99"},
+		{".99 nearest up", ninetyNine, "12.60", "12.99"},
+		{".99 already", ninetyNine, "12.99", "12.99"},
+		{".99 half", ninetyNine, "12.49", "12.99"}, // 12.50 is half way, away from zero
+		{".99 never zero", ninetyNine, "0.20", "0.99"},
+		{".99 up", ninetyNineUp, "12.34", "12.99"},
+		{".99 up exact", ninetyNineUp, "11.99", "11.99"},
+		{".99 down", ninetyNineDown, "12.98", "11.99"},
+		{"yen", yen, "1234.5", "1239"},
+		{"yen small", yen, "3", "9"},
+		{"decimals only", WooRoundingRule{Decimals: 2}, "1.005", "1.01"},
+		{"decimals only negative", WooRoundingRule{Decimals: 2}, "-1.005", "-1.01"},
+		{"zero stays zero", ninetyNine, "0", "0.00"},
+	}
+	for _, tt := range tests {
+		m, err := ParseMoney(tt.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := tt.rule.Apply(m).Format(tt.rule.Decimals); got != tt.want {
+			t.Errorf("%s: Apply(%s) = %s, want %s", tt.name, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCustomPrices(t *testing.T) {
+	pricing := WooCurrencyPricing{
+		Rates: map[string]WooMoney{"USD": NewMoney(108, 2), "JPY": NewMoney(1605, 1)},
+		Rounding: map[string]WooRoundingRule{
+			"JPY": {Step: NewMoney(10, 0), Ending: NewMoney(1, 0)},
+		},
+		DefaultRounding: WooRoundingRule{Decimals: 2},
+	}
+
+	prices, err := pricing.CustomPrices("10", "8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]WpmlPrice{
+		"USD": {RegularPrice: "10.80", SalePrice: "8.64"},
+		"JPY": {RegularPrice: "1609", SalePrice: "1289"},
+	}
+	for currency, price := range want {
+		if prices[currency] != price {
+			t.Errorf("%s = %+v, want %+v", currency, prices[currency], price)
+		}
+	}
+
+	pricing = WooCurrencyPricing{
+		Rates:           map[string]WooMoney{"EUR": NewMoney(1, 0)},
+		DefaultRounding: WooRoundingRule{Decimals: 2, Step: NewMoney(1, 0), Ending: NewMoney(1, 2), Mode: RoundUp},
+	}
+	if _, err := pricing.CustomPrices("12.50", "12.40"); err == nil {
+		t.Error("a sale price rounded to the regular price must be an error")
+	}
+	if prices, err := pricing.CustomPrices("", ""); prices != nil || err != nil {
+		t.Errorf("no regular price = %v, %v, want nil, nil", prices, err)
+	}
+	if _, err := pricing.Convert(NewMoney(1, 0), "CHF"); err == nil {
+		t.Error("a currency without rate must be an error")
+	}
+}
@@ -0,0 +1,23 @@
+package gowoocommerce
+
+// WooCustomer holds the commonly used fields of a customer
+type WooCustomer struct {
+	ID               int32       `json:"id,omitempty"` // read-only
+	Email            string      `json:"email,omitempty"`
+	FirstName        string      `json:"first_name,omitempty"`
+	LastName         string      `json:"last_name,omitempty"`
+	Username         string      `json:"username,omitempty"`
+	Role             string      `json:"role,omitempty"`             // read-only
+	DateCreatedGmt   WooTime     `json:"date_created_gmt,omitzero"`  // read-only
+	DateModifiedGmt  WooTime     `json:"date_modified_gmt,omitzero"` // read-only
+	Billing          WooAddress  `json:"billing,omitempty"`
+	Shipping         WooAddress  `json:"shipping,omitempty"`
+	IsPayingCustomer bool        `json:"is_paying_customer,omitempty"` // read-only
+	AvatarURL        string      `json:"avatar_url,omitempty"`         // read-only
+	MetaData         WooMetaData `json:"meta_data,omitempty"`
+}
+
+// GetID implements WooItem
+func (c WooCustomer) GetID() int32 {
+	return c.ID
+}
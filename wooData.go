@@ -0,0 +1,250 @@
+package gowoocommerce
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WooState is a state/province as listed by the data endpoints
+type WooState struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+// WooCountry is a country as listed by /data/countries
+type WooCountry struct {
+	Code   string     `json:"code"`
+	Name   string     `json:"name"`
+	States []WooState `json:"states,omitempty"`
+}
+
+// WooCurrency is a currency as listed by /data/currencies
+type WooCurrency struct {
+	Code   string `json:"code"`
+	Name   string `json:"name"`
+	Symbol string `json:"symbol"`
+}
+
+// WooContinentCountry is a country nested in a continent including its locale settings
+type WooContinentCountry struct {
+	Code          string     `json:"code"`
+	Name          string     `json:"name"`
+	CurrencyCode  string     `json:"currency_code,omitempty"`
+	CurrencyPos   string     `json:"currency_pos,omitempty"` // Options: left, right, left_space, right_space
+	DecimalSep    string     `json:"decimal_sep,omitempty"`
+	DimensionUnit string     `json:"dimension_unit,omitempty"`
+	NumDecimals   int32      `json:"num_decimals,omitempty"`
+	ThousandSep   string     `json:"thousand_sep,omitempty"`
+	WeightUnit    string     `json:"weight_unit,omitempty"`
+	States        []WooState `json:"states,omitempty"`
+}
+
+// WooContinent is a continent as listed by /data/continents
+type WooContinent struct {
+	Code      string                `json:"code"`
+	Name      string                `json:"name"`
+	Countries []WooContinentCountry `json:"countries,omitempty"`
+}
+
+// wooDataCache holds the reference data once it was loaded from the shop
+// the data rarely changes, so it is only fetched again after ClearDataCache
+type wooDataCache struct {
+	mu              sync.Mutex
+	countries       []WooCountry
+	currencies      []WooCurrency
+	currentCurrency *WooCurrency
+	continents      []WooContinent
+	priceFormat     *WooPriceFormat
+	location        *time.Location
+	media           map[string]WooMedia            // uploaded media by content hash
+	terms           map[string]WooTermTranslations // term translations by taxonomy endpoint
+}
+
+// GetCountries returns all countries (including their states) supported by the shop
+// the result is a copy of the cached data and can be modified
+// https://woocommerce.github.io/woocommerce-rest-api-docs/#list-all-countries
+func (w *WooConnection) GetCountries() ([]WooCountry, error) {
+	w.data.mu.Lock()
+	cached := w.data.countries
+	w.data.mu.Unlock()
+	if cached != nil {
+		return copyCountries(cached), nil
+	}
+
+	var countries []WooCountry
+	err := w.getData("/wp-json/wc/v3/data/countries", &countries)
+	if err != nil {
+		return nil, err
+	}
+	w.data.mu.Lock()
+	w.data.countries = countries
+	w.data.mu.Unlock()
+
+	return copyCountries(countries), nil
+}
+
+// GetCurrencies returns all currencies known to the shop
+// the result is a copy of the cached data and can be modified
+// https://woocommerce.github.io/woocommerce-rest-api-docs/#list-all-currencies
+func (w *WooConnection) GetCurrencies() ([]WooCurrency, error) {
+	w.data.mu.Lock()
+	cached := w.data.currencies
+	w.data.mu.Unlock()
+	if cached != nil {
+		return append([]WooCurrency(nil), cached...), nil
+	}
+
+	var currencies []WooCurrency
+	err := w.getData("/wp-json/wc/v3/data/currencies", &currencies)
+	if err != nil {
+		return nil, err
+	}
+	w.data.mu.Lock()
+	w.data.currencies = currencies
+	w.data.mu.Unlock()
+
+	return append([]WooCurrency(nil), currencies...), nil
+}
+
+// GetCurrentCurrency returns the currency the shop is currently set to
+// https://woocommerce.github.io/woocommerce-rest-api-docs/#retrieve-current-currency
+func (w *WooConnection) GetCurrentCurrency() (WooCurrency, error) {
+	w.data.mu.Lock()
+	cached := w.data.currentCurrency
+	w.data.mu.Unlock()
+	if cached != nil {
+		return *cached, nil
+	}
+
+	var currency WooCurrency
+	err := w.getData("/wp-json/wc/v3/data/currencies/current", &currency)
+	if err != nil {
+		return currency, err
+	}
+	w.data.mu.Lock()
+	w.data.currentCurrency = &currency
+	w.data.mu.Unlock()
+
+	return currency, nil
+}
+
+// GetContinents returns all continents with their countries and locale settings
+// the result is a copy of the cached data and can be modified
+// https://woocommerce.github.io/woocommerce-rest-api-docs/#list-all-continents
+func (w *WooConnection) GetContinents() ([]WooContinent, error) {
+	w.data.mu.Lock()
+	cached := w.data.continents
+	w.data.mu.Unlock()
+	if cached != nil {
+		return copyContinents(cached), nil
+	}
+
+	var continents []WooContinent
+	err := w.getData("/wp-json/wc/v3/data/continents", &continents)
+	if err != nil {
+		return nil, err
+	}
+	w.data.mu.Lock()
+	w.data.continents = continents
+	w.data.mu.Unlock()
+
+	return copyContinents(continents), nil
+}
+
+// copyCountries copies the countries including their states
+func copyCountries(countries []WooCountry) []WooCountry {
+	copied := make([]WooCountry, len(countries))
+	for i := range countries {
+		copied[i] = countries[i]
+		copied[i].States = append([]WooState(nil), countries[i].States...)
+	}
+	return copied
+}
+
+// copyContinents copies the continents including their countries and states
+func copyContinents(continents []WooContinent) []WooContinent {
+	copied := make([]WooContinent, len(continents))
+	for i := range continents {
+		copied[i] = continents[i]
+		copied[i].Countries = make([]WooContinentCountry, len(continents[i].Countries))
+		for j := range continents[i].Countries {
+			copied[i].Countries[j] = continents[i].Countries[j]
+			copied[i].Countries[j].States = append([]WooState(nil), continents[i].Countries[j].States...)
+		}
+	}
+	return copied
+}
+
+// ClearDataCache drops the cached reference data so the next call fetches it again
+func (w *WooConnection) ClearDataCache() {
+	w.data.mu.Lock()
+	defer w.data.mu.Unlock()
+
+	w.data.countries = nil
+	w.data.currencies = nil
+	w.data.currentCurrency = nil
+	w.data.continents = nil
+	w.data.priceFormat = nil
+	w.data.location = nil
+	w.data.media = nil
+	w.data.terms = nil
+}
+
+// IsValidState checks whether the shop supports the given country/state combination
+// countries without any states accept an empty state code only
+func (w *WooConnection) IsValidState(countryCode, stateCode string) (bool, error) {
+	countries, err := w.GetCountries()
+	if err != nil {
+		return false, err
+	}
+
+	for i := range countries {
+		if strings.EqualFold(countries[i].Code, countryCode) == false {
+			continue
+		}
+		if len(countries[i].States) == 0 {
+			return stateCode == "", nil
+		}
+		for j := range countries[i].States {
+			if strings.EqualFold(countries[i].States[j].Code, stateCode) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	return false, nil
+}
+
+// CurrencySymbol returns the symbol for the given currency code, false if the shop does not know the currency
+func (w *WooConnection) CurrencySymbol(currencyCode string) (string, bool, error) {
+	currencies, err := w.GetCurrencies()
+	if err != nil {
+		return "", false, err
+	}
+
+	for i := range currencies {
+		if strings.EqualFold(currencies[i].Code, currencyCode) {
+			return currencies[i].Symbol, true, nil
+		}
+	}
+
+	return "", false, nil
+}
+
+// getData sends a single GET request to one of the data endpoints and unmarshals the response into v
+func (w *WooConnection) getData(endpoint string, v interface{}) error {
+	if w.initialized == false {
+		return errors.New("Please initialize with your credentials first. WooConnection.Init()")
+	}
+
+	resp, err := WooGetRequest{Endpoint: endpoint}.Send(w)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(resp, v)
+}
@@ -0,0 +1,42 @@
+package gowoocommerce
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestGetCountriesCopy(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		fmt.Fprint(rw, `[{"code": "DE", "name": "Germany", "states": [{"code": "BY", "name": "Bavaria"}]}]`)
+	}))
+	defer srv.Close()
+
+	var w WooConnection
+	err := w.Init(srv.URL, "key", "secret", 10, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	countries, err := w.GetCountries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	countries[0].Name = "changed"
+	countries[0].States[0].Code = "XX"
+
+	countries, err = w.GetCountries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if countries[0].Name != "Germany" || countries[0].States[0].Code != "BY" {
+		t.Errorf("cached countries were modified: %+v", countries[0])
+	}
+	if requests.Load() != 1 {
+		t.Errorf("%d requests, want 1", requests.Load())
+	}
+}
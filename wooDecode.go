@@ -0,0 +1,371 @@
+package gowoocommerce
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// WooDecodeError is a field that could not be decoded, the field is left at its zero value
+type WooDecodeError struct {
+	Endpoint string `json:"endpoint,omitempty"`
+	ID       int64  `json:"id,omitempty"`  // ID of the item the field belongs to
+	SKU      string `json:"sku,omitempty"` // SKU of the item, if it has one
+	Field    string `json:"field"`         // path of the field, e.g. "dimensions.length" or "images[0].id"; empty for the whole item
+	Value    string `json:"value"`         // the raw JSON value
+	Message  string `json:"message"`
+}
+
+func (e WooDecodeError) Error() string {
+	return fmt.Sprintf("Unable to decode %s of item %d (%s) - %s: %s", e.Field, e.ID, e.SKU, e.Message, e.Value)
+}
+
+// wooDecoding holds the decoding setting and the fields that could not be decoded
+type wooDecoding struct {
+	mu     sync.Mutex
+	strict bool
+	report []WooDecodeError
+}
+
+// SetStrictDecoding makes list requests fail on the first field that can not be decoded
+// By default payloads are decoded leniently: numbers sent as strings (and the other way round),
+// false or [] instead of objects etc. are converted, anything else is recorded in DecodeReport.
+func (w *WooConnection) SetStrictDecoding(strict bool) {
+	w.decoding.mu.Lock()
+	defer w.decoding.mu.Unlock()
+
+	w.decoding.strict = strict
+}
+
+// DecodeReport returns the fields that could not be decoded since the last reset
+func (w *WooConnection) DecodeReport() []WooDecodeError {
+	w.decoding.mu.Lock()
+	defer w.decoding.mu.Unlock()
+
+	return append([]WooDecodeError(nil), w.decoding.report...)
+}
+
+// ResetDecodeReport drops the recorded decode errors
+func (w *WooConnection) ResetDecodeReport() {
+	w.decoding.mu.Lock()
+	defer w.decoding.mu.Unlock()
+
+	w.decoding.report = nil
+}
+
+func (w *WooConnection) strictDecoding() bool {
+	w.decoding.mu.Lock()
+	defer w.decoding.mu.Unlock()
+
+	return w.decoding.strict
+}
+
+func (w *WooConnection) recordDecodeErrors(endpoint string, errs []WooDecodeError) {
+	if len(errs) == 0 {
+		return
+	}
+	w.decoding.mu.Lock()
+	defer w.decoding.mu.Unlock()
+
+	for i := range errs {
+		errs[i].Endpoint = endpoint
+		fmt.Println(errs[i])
+	}
+	w.decoding.report = append(w.decoding.report, errs...)
+}
+
+// decodeList decodes a JSON array item by item, so a broken item never drops the rest of the page
+func decodeList[T any](body []byte) ([]T, []WooDecodeError, error) {
+	var raws []json.RawMessage
+	err := json.Unmarshal(body, &raws)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	items := make([]T, 0, len(raws))
+	var errs []WooDecodeError
+	for _, raw := range raws {
+		var item T
+		itemErrs, err := UnmarshalLenient(raw, &item)
+		if err != nil {
+			return nil, nil, err
+		}
+		errs = append(errs, itemErrs...)
+		items = append(items, item)
+	}
+	return items, errs, nil
+}
+
+// UnmarshalLenient decodes data into v like json.Unmarshal, but converts the inconsistent values plugins send
+// Fields that can not be converted either are left at their zero value and returned, err is only set if data is no valid JSON.
+func UnmarshalLenient(data []byte, v interface{}) ([]WooDecodeError, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return nil, errors.New("UnmarshalLenient needs a non-nil pointer")
+	}
+	if json.Valid(data) == false {
+		return nil, errors.New("Invalid JSON")
+	}
+
+	var errs []WooDecodeError
+	decodeLenient(data, rv.Elem(), "", &errs)
+	if len(errs) > 0 {
+		var key struct {
+			ID  int64  `json:"id"`
+			SKU string `json:"sku"`
+		}
+		var ignored []WooDecodeError
+		decodeLenient(data, reflect.ValueOf(&key).Elem(), "", &ignored)
+		for i := range errs {
+			errs[i].ID = key.ID
+			errs[i].SKU = key.SKU
+		}
+	}
+	return errs, nil
+}
+
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// decodeLenient decodes raw into v, falling back to conversions field by field
+func decodeLenient(raw json.RawMessage, v reflect.Value, path string, errs *[]WooDecodeError) {
+	raw = bytes.TrimSpace(raw)
+	err := json.Unmarshal(raw, v.Addr().Interface())
+	if err == nil {
+		return
+	}
+	v.Set(reflect.Zero(v.Type()))
+
+	fail := func(msg string) {
+		*errs = append(*errs, WooDecodeError{Field: strings.TrimPrefix(path, "."), Value: string(raw), Message: msg})
+	}
+
+	// false, "", [] and {} are what PHP sends for "nothing"
+	empty := isEmptyJSON(raw)
+
+	if reflect.PointerTo(v.Type()).Implements(unmarshalerType) {
+		if empty {
+			return
+		}
+		if s, ok := jsonScalarString(raw); ok && len(raw) > 0 && raw[0] != '"' {
+			// e.g. a number where a WooPrice or WooTime expects a string
+			if json.Unmarshal(mustMarshal(s), v.Addr().Interface()) == nil {
+				return
+			}
+			v.Set(reflect.Zero(v.Type()))
+		}
+		fail(err.Error())
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		if empty {
+			return
+		}
+		if s, ok := jsonScalarString(raw); ok {
+			v.SetString(s)
+			return
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if empty {
+			return
+		}
+		if f, ok := jsonNumber(raw); ok && f == float64(int64(f)) && v.OverflowInt(int64(f)) == false {
+			v.SetInt(int64(f))
+			return
+		}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if empty {
+			return
+		}
+		if f, ok := jsonNumber(raw); ok && f >= 0 && f == float64(uint64(f)) && v.OverflowUint(uint64(f)) == false {
+			v.SetUint(uint64(f))
+			return
+		}
+
+	case reflect.Float32, reflect.Float64:
+		if empty {
+			return
+		}
+		if f, ok := jsonNumber(raw); ok {
+			v.SetFloat(f)
+			return
+		}
+
+	case reflect.Bool:
+		if empty {
+			return
+		}
+		if s, ok := jsonScalarString(raw); ok {
+			switch strings.ToLower(strings.TrimSpace(s)) {
+			case "1", "true", "yes", "on":
+				v.SetBool(true)
+				return
+			case "0", "false", "no", "off":
+				return
+			}
+		}
+
+	case reflect.Pointer:
+		v.Set(reflect.New(v.Type().Elem()))
+		decodeLenient(raw, v.Elem(), path, errs)
+		return
+
+	case reflect.Struct:
+		var fields map[string]json.RawMessage
+		if json.Unmarshal(raw, &fields) == nil {
+			decodeStruct(fields, v, path, errs)
+			return
+		}
+		if empty {
+			return
+		}
+
+	case reflect.Slice:
+		var elems []json.RawMessage
+		if json.Unmarshal(raw, &elems) == nil {
+			decodeSlice(elems, v, path, errs)
+			return
+		}
+		// PHP arrays with gaps are sent as objects: {"0": ..., "2": ...}
+		var byKey map[string]json.RawMessage
+		if json.Unmarshal(raw, &byKey) == nil {
+			keys := make([]string, 0, len(byKey))
+			for key := range byKey {
+				keys = append(keys, key)
+			}
+			sort.Slice(keys, func(i, j int) bool {
+				a, errA := strconv.Atoi(keys[i])
+				b, errB := strconv.Atoi(keys[j])
+				if errA == nil && errB == nil {
+					return a < b
+				}
+				return keys[i] < keys[j]
+			})
+			elems = make([]json.RawMessage, len(keys))
+			for i := range keys {
+				elems[i] = byKey[keys[i]]
+			}
+			decodeSlice(elems, v, path, errs)
+			return
+		}
+		if empty {
+			return
+		}
+
+	case reflect.Map:
+		var byKey map[string]json.RawMessage
+		if v.Type().Key().Kind() == reflect.String && json.Unmarshal(raw, &byKey) == nil {
+			m := reflect.MakeMapWithSize(v.Type(), len(byKey))
+			for key, elemRaw := range byKey {
+				elem := reflect.New(v.Type().Elem()).Elem()
+				decodeLenient(elemRaw, elem, path+"."+key, errs)
+				m.SetMapIndex(reflect.ValueOf(key).Convert(v.Type().Key()), elem)
+			}
+			v.Set(m)
+			return
+		}
+		if empty {
+			return
+		}
+	}
+
+	fail(err.Error())
+}
+
+// decodeStruct decodes the fields of a JSON object one by one, keys are matched like encoding/json does
+func decodeStruct(fields map[string]json.RawMessage, v reflect.Value, path string, errs *[]WooDecodeError) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.IsExported() == false {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" && f.Anonymous && f.Type.Kind() == reflect.Struct {
+			// fields of embedded structs are promoted
+			decodeStruct(fields, v.Field(i), path, errs)
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		raw, ok := fields[name]
+		if ok == false {
+			for key := range fields {
+				if strings.EqualFold(key, name) {
+					raw, ok = fields[key], true
+					break
+				}
+			}
+		}
+		if ok {
+			decodeLenient(raw, v.Field(i), path+"."+name, errs)
+		}
+	}
+}
+
+func decodeSlice(elems []json.RawMessage, v reflect.Value, path string, errs *[]WooDecodeError) {
+	s := reflect.MakeSlice(v.Type(), len(elems), len(elems))
+	for i := range elems {
+		decodeLenient(elems[i], s.Index(i), fmt.Sprintf("%s[%d]", path, i), errs)
+	}
+	v.Set(s)
+}
+
+// isEmptyJSON reports whether raw is one of the "empty" values PHP sends: null, false, "", [] or {}
+func isEmptyJSON(raw []byte) bool {
+	switch string(raw) {
+	case "null", "false", `""`:
+		return true
+	}
+	compact := strings.Join(strings.Fields(string(raw)), "")
+	return compact == "[]" || compact == "{}"
+}
+
+// jsonScalarString returns a string, number or bool as string
+func jsonScalarString(raw []byte) (string, bool) {
+	var val interface{}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if dec.Decode(&val) != nil {
+		return "", false
+	}
+	switch s := val.(type) {
+	case string:
+		return s, true
+	case json.Number:
+		return s.String(), true
+	case bool:
+		if s {
+			return "1", true
+		}
+		return "", true
+	}
+	return "", false
+}
+
+// jsonNumber returns a number, numeric string or bool as float
+func jsonNumber(raw []byte) (float64, bool) {
+	s, ok := jsonScalarString(raw)
+	if ok == false {
+		return 0, false
+	}
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, true
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	return f, err == nil
+}
@@ -0,0 +1,243 @@
+package gowoocommerce
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// WooCheckpoint is the state of the incremental fetch of one resource
+type WooCheckpoint struct {
+	HighWaterMark    time.Time `json:"high_water_mark"`     // latest date_modified_gmt seen so far
+	MarkIDs          []int32   `json:"mark_ids,omitempty"`  // IDs already returned with exactly the high water mark
+	KnownIDs         []int32   `json:"known_ids,omitempty"` // all IDs found by the last deletion scan
+	LastDeletionScan time.Time `json:"last_deletion_scan,omitempty"`
+}
+
+// WooCheckpointStore persists checkpoints between runs, keyed by resource ("products", "orders", "customers")
+type WooCheckpointStore interface {
+	Load(resource string) (WooCheckpoint, error) // returns an empty checkpoint if there is none yet
+	Save(resource string, cp WooCheckpoint) error
+}
+
+// WooFileCheckpointStore keeps all checkpoints in a single JSON file
+type WooFileCheckpointStore struct {
+	Path string
+	mu   sync.Mutex
+}
+
+// Load implements WooCheckpointStore
+func (s *WooFileCheckpointStore) Load(resource string) (WooCheckpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	all, err := s.read()
+	if err != nil {
+		return WooCheckpoint{}, err
+	}
+	return all[resource], nil
+}
+
+// Save implements WooCheckpointStore
+func (s *WooFileCheckpointStore) Save(resource string, cp WooCheckpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	all, err := s.read()
+	if err != nil {
+		return err
+	}
+	all[resource] = cp
+
+	b, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.Path + ".tmp"
+	err = os.WriteFile(tmp, b, 0644)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.Path)
+}
+
+func (s *WooFileCheckpointStore) read() (map[string]WooCheckpoint, error) {
+	all := make(map[string]WooCheckpoint)
+	b, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return all, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(b, &all)
+	return all, err
+}
+
+// WooMemoryCheckpointStore keeps the checkpoints in memory only, e.g. for long running processes
+type WooMemoryCheckpointStore struct {
+	mu          sync.Mutex
+	checkpoints map[string]WooCheckpoint
+}
+
+// Load implements WooCheckpointStore
+func (s *WooMemoryCheckpointStore) Load(resource string) (WooCheckpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.checkpoints[resource], nil
+}
+
+// Save implements WooCheckpointStore
+func (s *WooMemoryCheckpointStore) Save(resource string, cp WooCheckpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.checkpoints == nil {
+		s.checkpoints = make(map[string]WooCheckpoint)
+	}
+	s.checkpoints[resource] = cp
+	return nil
+}
+
+// WooDeltaOptions configures the incremental fetch
+type WooDeltaOptions struct {
+	// DeletionScanInterval defines how often all IDs are compared with the last scan to find deletions
+	// 0 scans on every run, a negative value never scans
+	DeletionScanInterval time.Duration
+}
+
+// WooDelta holds the items changed since the last committed run
+// Items without a modified date can not be tracked, they are only returned by the first run.
+type WooDelta[T WooItem] struct {
+	Changed    []T     // created or modified items
+	DeletedIDs []int32 // IDs that disappeared since the last deletion scan, only set when a scan was due
+
+	resource   string
+	store      WooCheckpointStore
+	checkpoint WooCheckpoint
+}
+
+// Commit saves the new checkpoint, call it once the changes were processed successfully
+// without Commit the next run returns the same changes again
+func (d WooDelta[T]) Commit() error {
+	return d.store.Save(d.resource, d.checkpoint)
+}
+
+// FetchChangedProducts returns all products modified (or deleted) since the last committed run
+func (w *WooConnection) FetchChangedProducts(store WooCheckpointStore, opts WooDeltaOptions) (WooDelta[WooProduct], error) {
+	return fetchChanges(w, "products", "/wp-json/wc/v3/products?status=any", store, opts,
+		func(p WooProduct) WooTime { return p.DateModifiedGmt })
+}
+
+// FetchChangedOrders returns all orders modified (or deleted) since the last committed run
+func (w *WooConnection) FetchChangedOrders(store WooCheckpointStore, opts WooDeltaOptions) (WooDelta[WooOrder], error) {
+	return fetchChanges(w, "orders", "/wp-json/wc/v3/orders?status=any", store, opts,
+		func(o WooOrder) WooTime { return o.DateModifiedGmt })
+}
+
+// FetchChangedCustomers returns all customers modified (or deleted) since the last committed run
+// The customers endpoint does not support modified_after, so all customers are loaded and filtered locally.
+func (w *WooConnection) FetchChangedCustomers(store WooCheckpointStore, opts WooDeltaOptions) (WooDelta[WooCustomer], error) {
+	return fetchChanges(w, "customers", "/wp-json/wc/v3/customers?role=all", store, opts,
+		func(c WooCustomer) WooTime { return c.DateModifiedGmt })
+}
+
+// fetchChanges loads the items modified after the high water mark and, if due, scans all IDs for deletions
+func fetchChanges[T WooItem](w *WooConnection, resource, endpoint string, store WooCheckpointStore, opts WooDeltaOptions, modified func(T) WooTime) (WooDelta[T], error) {
+	delta := WooDelta[T]{resource: resource, store: store}
+
+	if w.initialized == false {
+		return delta, errors.New("Please initialize with your credentials first. WooConnection.Init()")
+	}
+	if store == nil {
+		return delta, errors.New("No checkpoint store given")
+	}
+
+	cp, err := store.Load(resource)
+	if err != nil {
+		return delta, fmt.Errorf("Unable to load checkpoint for %s - %v", resource, err)
+	}
+	delta.checkpoint = cp
+
+	endpoint = setQueryParam(endpoint, "orderby", "id")
+	endpoint = setQueryParam(endpoint, "order", "asc")
+
+	// modified_after is exclusive and has a resolution of one second: start one second earlier
+	// and skip what was returned before, so items changed later in the same second are not lost
+	changedEndpoint := endpoint
+	mark := cp.HighWaterMark.Truncate(time.Second)
+	if mark.IsZero() == false {
+		changedEndpoint = setQueryParam(changedEndpoint, "modified_after", mark.Add(-time.Second).UTC().Format(wooTimeFormat))
+		changedEndpoint = setQueryParam(changedEndpoint, "dates_are_gmt", "true")
+	}
+	seen := make(map[int32]bool, len(cp.MarkIDs))
+	for _, id := range cp.MarkIDs {
+		seen[id] = true
+	}
+
+	newMark := mark
+	markIDs := append([]int32(nil), cp.MarkIDs...)
+	for item, err := range Paginate[T](w, changedEndpoint, defaultPageSize) {
+		if err != nil {
+			return delta, err
+		}
+		ts := modified(item).Time.Truncate(time.Second)
+		if mark.IsZero() == false {
+			if ts.IsZero() || ts.Before(mark) {
+				continue // endpoints without modified_after support return everything
+			}
+			if ts.Equal(mark) && seen[item.GetID()] {
+				continue
+			}
+		}
+		if ts.After(newMark) {
+			newMark = ts
+			markIDs = nil
+		}
+		if ts.IsZero() == false && ts.Equal(newMark) {
+			markIDs = append(markIDs, item.GetID())
+		}
+		delta.Changed = append(delta.Changed, item)
+	}
+	delta.checkpoint.HighWaterMark = newMark
+	delta.checkpoint.MarkIDs = markIDs
+
+	scanDue := opts.DeletionScanInterval >= 0 && time.Since(cp.LastDeletionScan) >= opts.DeletionScanInterval
+	if scanDue == false {
+		return delta, nil
+	}
+
+	// only the IDs are requested to keep the scan cheap
+	var ids []int32
+	for item, err := range Paginate[struct {
+		ID int32 `json:"id"`
+	}](w, setQueryParam(endpoint, "_fields", "id"), defaultPageSize) {
+		if err != nil {
+			return delta, err
+		}
+		ids = append(ids, item.ID)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	// the first scan only records the IDs, there is nothing to compare with yet
+	if cp.LastDeletionScan.IsZero() == false {
+		current := make(map[int32]bool, len(ids))
+		for _, id := range ids {
+			current[id] = true
+		}
+		for _, id := range cp.KnownIDs {
+			if current[id] == false {
+				delta.DeletedIDs = append(delta.DeletedIDs, id)
+			}
+		}
+	}
+	delta.checkpoint.KnownIDs = ids
+	delta.checkpoint.LastDeletionScan = time.Now().UTC()
+
+	return delta, nil
+}
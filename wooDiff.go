@@ -0,0 +1,379 @@
+package gowoocommerce
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+)
+
+// WooCatalogSource provides the products of a catalog to compare
+type WooCatalogSource interface {
+	LoadProducts() ([]WooProduct, error)
+}
+
+// WooLiveCatalog loads the products from a shop
+type WooLiveCatalog struct {
+	Conn *WooConnection
+}
+
+// LoadProducts implements WooCatalogSource
+func (c WooLiveCatalog) LoadProducts() ([]WooProduct, error) {
+	return c.Conn.GetProducts(NewProductQuery().Status("any"))
+}
+
+// WooSnapshotCatalog loads the products from a snapshot archive written by ExportSnapshot
+type WooSnapshotCatalog struct {
+	Path string
+}
+
+// LoadProducts implements WooCatalogSource
+func (c WooSnapshotCatalog) LoadProducts() ([]WooProduct, error) {
+	f, err := os.Open(c.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	snapshot, err := ReadSnapshot(f)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot.Products()
+}
+
+// WooDiffOptions configures the catalog comparison
+type WooDiffOptions struct {
+	IgnoreFields []string // additional JSON field names to ignore, e.g. "images"
+	// CompareIDs also compares IDs and ID references (categories, tags, upsells, ...)
+	// leave it false to compare different shops, categories and tags are then compared by slug
+	CompareIDs bool
+}
+
+// WooProductDiff lists the field changes of one product, matched by SKU
+type WooProductDiff struct {
+	SKU    string           `json:"sku"`
+	Fields []WooFieldChange `json:"fields"`
+}
+
+// WooCatalogDiff is the difference between catalog A (old) and catalog B (new)
+type WooCatalogDiff struct {
+	Added   []WooProductDiff `json:"added"`   // only in B, Fields hold the new values
+	Removed []WooProductDiff `json:"removed"` // only in A, Fields hold the old values
+	Changed []WooProductDiff `json:"changed"` // in both, Fields hold the old and new values
+
+	// Ambiguous lists keys shared by several products of a catalog and products without SKU and slug,
+	// these products are not compared
+	Ambiguous []string `json:"ambiguous,omitempty"`
+}
+
+// idReferenceFields hold IDs which differ between shops
+var idReferenceFields = []string{"upsell_ids", "cross_sell_ids", "grouped_products", "parent_id"}
+
+// DiffCatalogs loads both catalogs and compares them
+func DiffCatalogs(a, b WooCatalogSource, opts WooDiffOptions) (WooCatalogDiff, error) {
+	productsA, err := a.LoadProducts()
+	if err != nil {
+		return WooCatalogDiff{}, fmt.Errorf("Unable to load catalog A - %v", err)
+	}
+	productsB, err := b.LoadProducts()
+	if err != nil {
+		return WooCatalogDiff{}, fmt.Errorf("Unable to load catalog B - %v", err)
+	}
+	return DiffProducts(productsA, productsB, opts)
+}
+
+// DiffProducts compares two product lists by SKU; products without SKU are matched by slug
+func DiffProducts(a, b []WooProduct, opts WooDiffOptions) (WooCatalogDiff, error) {
+	var diff WooCatalogDiff
+
+	ignore := make(map[string]bool)
+	for field := range readOnlyProductFields {
+		ignore[field] = true
+	}
+	for _, field := range opts.IgnoreFields {
+		ignore[field] = true
+	}
+	if opts.CompareIDs == true {
+		delete(ignore, "id")
+	} else {
+		for _, field := range idReferenceFields {
+			ignore[field] = true
+		}
+	}
+
+	fieldsA, ambiguousA, err := diffFields(a, ignore, opts.CompareIDs)
+	if err != nil {
+		return diff, err
+	}
+	fieldsB, ambiguousB, err := diffFields(b, ignore, opts.CompareIDs)
+	if err != nil {
+		return diff, err
+	}
+
+	// a key that is ambiguous on one side can not be matched on the other side either
+	for _, side := range []struct {
+		name      string
+		ambiguous map[string]int
+	}{{"A", ambiguousA}, {"B", ambiguousB}} {
+		for _, key := range sortedCountKeys(side.ambiguous) {
+			if key == "slug:" {
+				diff.Ambiguous = append(diff.Ambiguous, fmt.Sprintf("%s: %d products without SKU and slug", side.name, side.ambiguous[key]))
+			} else {
+				diff.Ambiguous = append(diff.Ambiguous, fmt.Sprintf("%s: %d products with key %q", side.name, side.ambiguous[key], key))
+			}
+			delete(fieldsA, key)
+			delete(fieldsB, key)
+		}
+	}
+
+	for _, key := range sortedDiffKeys(fieldsA) {
+		old := fieldsA[key]
+		cur, ok := fieldsB[key]
+		if ok == false {
+			d := WooProductDiff{SKU: key}
+			for _, field := range sortedKeys(old) {
+				d.Fields = append(d.Fields, WooFieldChange{Field: field, Old: old[field]})
+			}
+			diff.Removed = append(diff.Removed, d)
+			continue
+		}
+
+		d := WooProductDiff{SKU: key}
+		union := make(map[string]interface{}, len(old)+len(cur))
+		for field := range old {
+			union[field] = nil
+		}
+		for field := range cur {
+			union[field] = nil
+		}
+		for _, field := range sortedKeys(union) {
+			if reflect.DeepEqual(old[field], cur[field]) {
+				continue
+			}
+			d.Fields = append(d.Fields, WooFieldChange{Field: field, Old: old[field], New: cur[field]})
+		}
+		if len(d.Fields) > 0 {
+			diff.Changed = append(diff.Changed, d)
+		}
+	}
+
+	for _, key := range sortedDiffKeys(fieldsB) {
+		if _, ok := fieldsA[key]; ok {
+			continue
+		}
+		d := WooProductDiff{SKU: key}
+		for _, field := range sortedKeys(fieldsB[key]) {
+			d.Fields = append(d.Fields, WooFieldChange{Field: field, New: fieldsB[key][field]})
+		}
+		diff.Added = append(diff.Added, d)
+	}
+
+	return diff, nil
+}
+
+// diffFields returns the comparable fields of every product keyed by SKU (or slug)
+// and the keys that can not identify a single product with the number of their products
+func diffFields(products []WooProduct, ignore map[string]bool, compareIDs bool) (map[string]map[string]interface{}, map[string]int, error) {
+	byKey := make(map[string]map[string]interface{}, len(products))
+	counts := make(map[string]int, len(products))
+	for i := range products {
+		key := products[i].SKU
+		if key == "" {
+			key = "slug:" + products[i].Slug
+		}
+		counts[key]++
+		fields, err := productFields(products[i], ignore)
+		if err != nil {
+			return nil, nil, err
+		}
+		if compareIDs == false {
+			for _, field := range []string{"categories", "tags"} {
+				fields[field] = termSlugs(fields[field])
+				if fields[field] == nil {
+					delete(fields, field)
+				}
+			}
+			for _, field := range []string{"images", "attributes", "default_attributes", "meta_data"} {
+				if list, ok := fields[field].([]interface{}); ok {
+					for _, entry := range list {
+						if m, ok := entry.(map[string]interface{}); ok {
+							delete(m, "id")
+						}
+					}
+				}
+			}
+		}
+		byKey[key] = fields
+	}
+
+	ambiguous := make(map[string]int)
+	for key, n := range counts {
+		if n > 1 || key == "slug:" {
+			ambiguous[key] = n
+		}
+	}
+	return byKey, ambiguous, nil
+}
+
+// termSlugs reduces a list of categories/tags to their sorted slugs (names if there is no slug)
+func termSlugs(v interface{}) interface{} {
+	list, ok := v.([]interface{})
+	if ok == false {
+		return v
+	}
+	var slugs []string
+	for _, entry := range list {
+		m, ok := entry.(map[string]interface{})
+		if ok == false {
+			continue
+		}
+		if slug, ok := m["slug"].(string); ok && slug != "" {
+			slugs = append(slugs, slug)
+		} else if name, ok := m["name"].(string); ok {
+			slugs = append(slugs, name)
+		}
+	}
+	if len(slugs) == 0 {
+		return nil
+	}
+	sort.Strings(slugs)
+	result := make([]interface{}, len(slugs))
+	for i := range slugs {
+		result[i] = slugs[i]
+	}
+	return result
+}
+
+func sortedCountKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedDiffKeys(m map[string]map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// WriteText renders the diff as plain text, one line per field change
+func (d WooCatalogDiff) WriteText(wr io.Writer) error {
+	sections := []struct {
+		title string
+		diffs []WooProductDiff
+	}{
+		{"Added", d.Added},
+		{"Removed", d.Removed},
+		{"Changed", d.Changed},
+	}
+
+	for _, s := range sections {
+		_, err := fmt.Fprintf(wr, "%s: %d products\n", s.title, len(s.diffs))
+		if err != nil {
+			return err
+		}
+		for _, pd := range s.diffs {
+			_, err = fmt.Fprintf(wr, "  %s\n", pd.SKU)
+			if err != nil {
+				return err
+			}
+			for _, f := range pd.Fields {
+				_, err = fmt.Fprintf(wr, "    %s: %s -> %s\n", f.Field, diffValue(f.Old), diffValue(f.New))
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for _, a := range d.Ambiguous {
+		_, err := fmt.Fprintf(wr, "Not compared, %s\n", a)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteJSON renders the diff as indented JSON
+func (d WooCatalogDiff) WriteJSON(wr io.Writer) error {
+	enc := json.NewEncoder(wr)
+	enc.SetIndent("", "  ")
+	return enc.Encode(d)
+}
+
+var diffHTMLTemplate = template.Must(template.New("diff").Funcs(template.FuncMap{"value": diffValue}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Catalog diff</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+td, th { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; }
+.old { background: #fdd; }
+.new { background: #dfd; }
+</style>
+</head>
+<body>
+{{range .Sections}}
+<h2>{{.Title}} ({{len .Diffs}} products)</h2>
+{{if .Diffs}}
+<table>
+<tr><th>SKU</th><th>Field</th><th>Old</th><th>New</th></tr>
+{{range .Diffs}}{{$sku := .SKU}}{{range .Fields}}
+<tr><td>{{$sku}}</td><td>{{.Field}}</td><td class="old">{{value .Old}}</td><td class="new">{{value .New}}</td></tr>
+{{end}}{{end}}
+</table>
+{{end}}
+{{end}}
+{{with .Ambiguous}}
+<h2>Not compared</h2>
+<ul>{{range .}}<li>{{.}}</li>{{end}}</ul>
+{{end}}
+</body>
+</html>
+`))
+
+// WriteHTML renders the diff as standalone HTML page
+func (d WooCatalogDiff) WriteHTML(wr io.Writer) error {
+	type section struct {
+		Title string
+		Diffs []WooProductDiff
+	}
+	return diffHTMLTemplate.Execute(wr, struct {
+		Sections  []section
+		Ambiguous []string
+	}{
+		Sections: []section{
+			{"Added", d.Added},
+			{"Removed", d.Removed},
+			{"Changed", d.Changed},
+		},
+		Ambiguous: d.Ambiguous,
+	})
+}
+
+// diffValue formats a field value for the text and HTML output
+func diffValue(v interface{}) string {
+	if v == nil {
+		return "-"
+	}
+	if s, ok := v.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
@@ -0,0 +1,189 @@
+package gowoocommerce
+
+import (
+	"encoding/json"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// WooMutation is a mutating request captured in dry-run mode
+type WooMutation struct {
+	Method   string          `json:"method"`
+	Endpoint string          `json:"endpoint"`
+	Body     json.RawMessage `json:"body,omitempty"`
+	Create   int             `json:"create,omitempty"` // number of items that would have been created
+	IDs      []int32         `json:"ids,omitempty"`    // IDs of the items that would have been updated/deleted
+	SKUs     []string        `json:"skus,omitempty"`   // SKUs of all items in the body
+}
+
+// wooDryRun holds the dry-run setting and the captured mutations
+type wooDryRun struct {
+	mu          sync.Mutex
+	enabled     bool
+	mutations   []WooMutation
+	placeholder int32 // last placeholder ID handed out for a simulated create
+}
+
+// SetDryRun enables or disables the dry-run mode
+// In dry-run mode GET requests are sent as usual, every POST/PUT/DELETE is only recorded
+// and answered locally, so e.g. PurgeProducts or BatchUpdate can be reviewed with DryRunReport.
+// Simulated creates are answered with distinct negative placeholder IDs.
+func (w *WooConnection) SetDryRun(enabled bool) {
+	w.dryRun.mu.Lock()
+	defer w.dryRun.mu.Unlock()
+
+	w.dryRun.enabled = enabled
+}
+
+// DryRunReport returns all mutations captured since the dry-run mode was enabled or reset
+func (w *WooConnection) DryRunReport() []WooMutation {
+	w.dryRun.mu.Lock()
+	defer w.dryRun.mu.Unlock()
+
+	return append([]WooMutation(nil), w.dryRun.mutations...)
+}
+
+// ResetDryRunReport drops the captured mutations
+func (w *WooConnection) ResetDryRunReport() {
+	w.dryRun.mu.Lock()
+	defer w.dryRun.mu.Unlock()
+
+	w.dryRun.mutations = nil
+}
+
+// captureDryRun records a mutating request and returns a simulated response, false if it has to be sent
+func (w *WooConnection) captureDryRun(method, endpoint string, body []byte) ([]byte, bool) {
+	if method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions {
+		return nil, false
+	}
+
+	w.dryRun.mu.Lock()
+	defer w.dryRun.mu.Unlock()
+
+	if w.dryRun.enabled == false {
+		return nil, false
+	}
+
+	m := WooMutation{
+		Method:   method,
+		Endpoint: endpoint,
+	}
+	// non-JSON bodies (e.g. media uploads) are not recorded
+	if len(body) > 0 && json.Valid(body) {
+		m.Body = append(json.RawMessage(nil), body...)
+	}
+	rsp := w.dryRun.describeMutation(&m, body)
+	w.dryRun.mutations = append(w.dryRun.mutations, m)
+
+	return rsp, true
+}
+
+// describeMutation fills in the affected IDs/SKUs and builds a response mirroring the payload
+// batch payloads are answered in the shape of a batch response so ParseBatchResponse keeps working
+func (d *wooDryRun) describeMutation(m *WooMutation, body []byte) []byte {
+	type item struct {
+		ID  int32  `json:"id,omitempty"`
+		SKU string `json:"sku,omitempty"`
+	}
+
+	// single item endpoints, e.g. /wp-json/wc/v3/products/15?force=true
+	base := endpointPath(m.Endpoint)
+	if id, err := strconv.ParseInt(path.Base(base), 10, 32); err == nil {
+		m.IDs = append(m.IDs, int32(id))
+	}
+
+	if strings.HasSuffix(base, "/batch") {
+		var batch struct {
+			Create []json.RawMessage `json:"create"`
+			Update []json.RawMessage `json:"update"`
+			Delete []int32           `json:"delete"`
+		}
+		json.Unmarshal(body, &batch)
+
+		var rsp struct {
+			Create []json.RawMessage `json:"create,omitempty"`
+			Update []json.RawMessage `json:"update,omitempty"`
+			Delete []item            `json:"delete,omitempty"`
+		}
+		m.Create = len(batch.Create)
+		for _, raw := range batch.Create {
+			rsp.Create = append(rsp.Create, d.withPlaceholderID(raw))
+		}
+		rsp.Update = batch.Update
+		for _, raw := range append(append([]json.RawMessage(nil), batch.Create...), batch.Update...) {
+			var it item
+			json.Unmarshal(raw, &it)
+			if it.ID != 0 {
+				m.IDs = append(m.IDs, it.ID)
+			}
+			if it.SKU != "" {
+				m.SKUs = append(m.SKUs, it.SKU)
+			}
+		}
+		for _, id := range batch.Delete {
+			m.IDs = append(m.IDs, id)
+			rsp.Delete = append(rsp.Delete, item{ID: id})
+		}
+
+		b, _ := json.Marshal(rsp)
+		return b
+	}
+
+	if len(body) > 0 && json.Valid(body) == false {
+		if len(m.IDs) == 0 && m.Method == http.MethodPost {
+			m.Create = 1
+			return d.withPlaceholderID([]byte("{}"))
+		}
+		return []byte("{}")
+	}
+
+	var it item
+	if json.Unmarshal(body, &it) == nil {
+		if it.ID != 0 {
+			m.IDs = append(m.IDs, it.ID)
+		} else if len(m.IDs) == 0 && m.Method == http.MethodPost {
+			m.Create = 1
+		}
+		if it.SKU != "" {
+			m.SKUs = append(m.SKUs, it.SKU)
+		}
+	}
+
+	if m.Create == 1 {
+		if len(body) == 0 {
+			return d.withPlaceholderID([]byte("{}"))
+		}
+		return d.withPlaceholderID(body)
+	}
+	if len(body) == 0 {
+		return []byte("{}")
+	}
+	return body
+}
+
+// withPlaceholderID sets the next negative placeholder ID on a simulated create,
+// so the created items can be told apart; payloads which are no JSON object are returned as they are
+func (d *wooDryRun) withPlaceholderID(raw json.RawMessage) json.RawMessage {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(raw, &fields) != nil || fields == nil {
+		return raw
+	}
+	d.placeholder--
+	fields["id"], _ = json.Marshal(d.placeholder)
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return raw
+	}
+	return b
+}
+
+// endpointPath strips the query string from an endpoint
+func endpointPath(endpoint string) string {
+	if idx := strings.Index(endpoint, "?"); idx >= 0 {
+		return endpoint[:idx]
+	}
+	return endpoint
+}
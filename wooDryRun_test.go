@@ -0,0 +1,40 @@
+package gowoocommerce
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDryRunPlaceholderIDs(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected %s %s", r.Method, r.URL)
+	}))
+	defer srv.Close()
+
+	var w WooConnection
+	err := w.Init(srv.URL, "key", "secret", 2, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.SetDryRun(true)
+
+	items := []WooItem{WooProduct{SKU: "a"}, WooProduct{SKU: "b"}, WooProduct{SKU: "c"}}
+	rsp, err := w.BatchCreate("/wp-json/wc/v3/products/batch", items, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rsp.Create) != 3 {
+		t.Fatalf("%d created, want 3", len(rsp.Create))
+	}
+	seen := make(map[int32]bool)
+	for _, c := range rsp.Create {
+		if c.ID >= 0 || seen[c.ID] {
+			t.Errorf("%s got ID %d, want a distinct negative placeholder", c.SKU, c.ID)
+		}
+		seen[c.ID] = true
+	}
+	if report := w.DryRunReport(); len(report) != 2 || report[0].Create+report[1].Create != 3 {
+		t.Errorf("report = %+v, want 2 batches creating 3 items", report)
+	}
+}
@@ -0,0 +1,391 @@
+package gowoocommerce
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WooImageStore remembers which media ID an image source (URL or file) was uploaded as
+type WooImageStore interface {
+	Lookup(source string) (int32, bool, error)
+	Remember(source string, mediaID int32) error
+}
+
+// WooFileImageStore keeps the image map in a JSON lines file, new entries are appended
+type WooFileImageStore struct {
+	Path string
+	mu   sync.Mutex
+	ids  map[string]int32
+}
+
+type imageStoreEntry struct {
+	Source  string `json:"source"`
+	MediaID int32  `json:"media_id"`
+}
+
+// Lookup implements WooImageStore
+func (s *WooFileImageStore) Lookup(source string) (int32, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.load()
+	if err != nil {
+		return 0, false, err
+	}
+	id, ok := s.ids[source]
+	return id, ok, nil
+}
+
+// Remember implements WooImageStore
+func (s *WooFileImageStore) Remember(source string, mediaID int32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.load()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(s.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	b, err := json.Marshal(imageStoreEntry{Source: source, MediaID: mediaID})
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(b, '\n'))
+	if err != nil {
+		return err
+	}
+	s.ids[source] = mediaID
+	return nil
+}
+
+// load reads the file once, later entries win
+func (s *WooFileImageStore) load() error {
+	if s.ids != nil {
+		return nil
+	}
+	ids := make(map[string]int32)
+	f, err := os.Open(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		s.ids = ids
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var e imageStoreEntry
+		if json.Unmarshal(scanner.Bytes(), &e) != nil {
+			continue // a line cut off by a crash
+		}
+		ids[e.Source] = e.MediaID
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	s.ids = ids
+	return nil
+}
+
+// WooMemoryImageStore keeps the image map in memory only
+type WooMemoryImageStore struct {
+	mu  sync.Mutex
+	ids map[string]int32
+}
+
+// Lookup implements WooImageStore
+func (s *WooMemoryImageStore) Lookup(source string) (int32, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, ok := s.ids[source]
+	return id, ok, nil
+}
+
+// Remember implements WooImageStore
+func (s *WooMemoryImageStore) Remember(source string, mediaID int32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ids == nil {
+		s.ids = make(map[string]int32)
+	}
+	s.ids[source] = mediaID
+	return nil
+}
+
+// WooImageReport lists what ResolveImages did
+type WooImageReport struct {
+	Reused   int      // images found in the store or in the media library
+	Uploaded int      // images uploaded
+	Failed   []string // sources that could not be resolved, they are left to WooCommerce's sideloading
+}
+
+// ResolveImages replaces the SRC of the products' images by media IDs, so WooCommerce does not sideload
+// another copy on every sync. Sources known to the store are reused if the media still exists, all others are
+// downloaded (or read from disk for file:// URLs and absolute paths) and uploaded with UploadMedia, which skips
+// content that is already in the media library. The products are modified in place.
+func (w *WooConnection) ResolveImages(products []WooProduct, store WooImageStore, verbose bool) (WooImageReport, error) {
+	var report WooImageReport
+
+	if w.initialized == false {
+		return report, errors.New("Please initialize with your credentials first. WooConnection.Init()")
+	}
+	if store == nil {
+		return report, errors.New("No image store given")
+	}
+
+	// media deleted since (e.g. by FindOrphanedMedia and DeleteMedia) must not be sent, they are uploaded again
+	known := make(map[string]int32)
+	var knownIDs []int32
+	for i := range products {
+		for _, img := range products[i].Images {
+			if img.ID != 0 || img.SRC == "" {
+				continue
+			}
+			if _, ok := known[img.SRC]; ok {
+				continue
+			}
+			id, ok, err := store.Lookup(img.SRC)
+			if err != nil {
+				return report, err
+			}
+			if ok {
+				known[img.SRC] = id
+				knownIDs = append(knownIDs, id)
+			}
+		}
+	}
+	existing, err := w.existingMediaIDs(knownIDs)
+	if err != nil {
+		return report, err
+	}
+
+	for i := range products {
+		for j := range products[i].Images {
+			img := &products[i].Images[j]
+			if img.ID != 0 || img.SRC == "" {
+				continue
+			}
+
+			id, ok := known[img.SRC]
+			ok = ok && existing[id]
+			if ok == false {
+				var reused bool
+				id, reused, err = w.uploadImageSource(img.SRC, img.Name, img.Alt)
+				if err != nil || id == 0 {
+					fmt.Println(err)
+					report.Failed = append(report.Failed, img.SRC)
+					continue
+				}
+				err = store.Remember(img.SRC, id)
+				if err != nil {
+					return report, err
+				}
+				known[img.SRC] = id
+				existing[id] = true
+				if reused == false {
+					report.Uploaded++
+					if verbose == true {
+						fmt.Printf("uploaded %s as media %d\n", img.SRC, id)
+					}
+				} else {
+					report.Reused++
+				}
+			} else {
+				report.Reused++
+			}
+
+			img.ID = id
+			img.SRC = ""
+		}
+	}
+
+	if len(report.Failed) > 0 {
+		return report, fmt.Errorf("%d images could not be resolved", len(report.Failed))
+	}
+	return report, nil
+}
+
+// existingMediaIDs returns which of the media IDs still exist in the media library
+func (w *WooConnection) existingMediaIDs(ids []int32) (map[int32]bool, error) {
+	existing := make(map[int32]bool, len(ids))
+	for start := 0; start < len(ids); start += defaultPageSize {
+		end := min(start+defaultPageSize, len(ids))
+		endpoint := "/wp-json/wp/v2/media?_fields=id&include=" + joinIDs(ids[start:end])
+		for m, err := range Paginate[WooMedia](w, endpoint, defaultPageSize) {
+			if err != nil {
+				return nil, err
+			}
+			existing[m.ID] = true
+		}
+	}
+	return existing, nil
+}
+
+// imageDownloadClient downloads image sources, a stalled host must not block a sync forever
+var imageDownloadClient = &http.Client{Timeout: 2 * time.Minute}
+
+// uploadImageSource uploads an image from an http(s) URL, a file:// URL or an absolute local path
+func (w *WooConnection) uploadImageSource(source, title, alt string) (int32, bool, error) {
+	opts := WooMediaOptions{Title: title, AltText: alt}
+
+	if filepath.IsAbs(source) {
+		media, reused, err := w.UploadMediaFile(source, opts)
+		return media.ID, reused, err
+	}
+	u, err := url.Parse(source)
+	if err != nil {
+		return 0, false, fmt.Errorf("Invalid image source %q - %v", source, err)
+	}
+	switch u.Scheme {
+	case "file":
+		media, reused, err := w.UploadMediaFile(u.Path, opts)
+		return media.ID, reused, err
+	case "http", "https":
+	default:
+		return 0, false, fmt.Errorf("Invalid image source %q, expected an http(s) URL, a file:// URL or an absolute path", source)
+	}
+
+	rsp, err := imageDownloadClient.Get(source)
+	if err != nil {
+		return 0, false, err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, rsp.Body)
+		return 0, false, fmt.Errorf("Unable to download %s - %s", source, rsp.Status)
+	}
+
+	opts.Filename = path.Base(u.Path)
+	if opts.Filename == "" || opts.Filename == "/" || opts.Filename == "." {
+		opts.Filename = "image"
+	}
+	opts.ContentType = strings.TrimSpace(strings.Split(rsp.Header.Get("Content-Type"), ";")[0])
+	media, reused, err := w.UploadMedia(rsp.Body, opts)
+	return media.ID, reused, err
+}
+
+// FindOrphanedMedia returns the images of the media library that no product, variation or category uses
+// Products in the trash count as users. Only WooCommerce references are checked: images used in posts
+// or pages are returned too, WooMedia.Post tells which post an attachment was uploaded to.
+func (w *WooConnection) FindOrphanedMedia(verbose bool) ([]WooMedia, error) {
+	if w.initialized == false {
+		return nil, errors.New("Please initialize with your credentials first. WooConnection.Init()")
+	}
+
+	used, err := w.usedImageIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	var orphans []WooMedia
+	total := 0
+	for m, err := range Paginate[WooMedia](w, "/wp-json/wp/v2/media?media_type=image", defaultPageSize) {
+		if err != nil {
+			return orphans, err
+		}
+		total++
+		if used[m.ID] == false {
+			orphans = append(orphans, m)
+		}
+	}
+	if verbose == true {
+		fmt.Printf("%d of %d images are not used by any product\n", len(orphans), total)
+	}
+
+	return orphans, nil
+}
+
+// usedImageIDs returns the IDs of all images used by products (including the trash), variations and categories
+func (w *WooConnection) usedImageIDs() (map[int32]bool, error) {
+	used := make(map[int32]bool)
+	var variable []int32
+	// status=any does not include the trash
+	for _, status := range []string{"any", "trash"} {
+		endpoint := "/wp-json/wc/v3/products?status=" + status + "&_fields=id,type,images"
+		for p, err := range Paginate[WooProduct](w, endpoint, defaultPageSize) {
+			if err != nil {
+				return nil, err
+			}
+			for _, img := range p.Images {
+				used[img.ID] = true
+			}
+			if p.Type == ProductTypeVariable {
+				variable = append(variable, p.GetID())
+			}
+		}
+	}
+
+	for _, id := range variable {
+		endpoint := fmt.Sprintf("/wp-json/wc/v3/products/%d/variations?_fields=id,image", id)
+		for v, err := range Paginate[struct {
+			Image WooImage `json:"image"`
+		}](w, endpoint, defaultPageSize) {
+			if err != nil {
+				return nil, err
+			}
+			used[v.Image.ID] = true
+		}
+	}
+
+	categories, err := w.GetCategories(nil)
+	if err != nil {
+		return nil, err
+	}
+	for i := range categories {
+		used[categories[i].Image.ID] = true
+	}
+
+	return used, nil
+}
+
+// DeleteMedia permanently deletes media attachments and returns the IDs that were deleted
+func (w *WooConnection) DeleteMedia(ids []int32, verbose bool) ([]int32, error) {
+	if w.initialized == false {
+		return nil, errors.New("Please initialize with your credentials first. WooConnection.Init()")
+	}
+
+	requests := make([]WooRequest, len(ids))
+	for i, id := range ids {
+		requests[i] = WooDeleteRequest{
+			Endpoint: fmt.Sprintf("/wp-json/wp/v2/media/%d?force=true", id),
+		}
+	}
+
+	var removed []int32
+	_, err := w.executeRequests(requests, false, verbose, func(idx int, err error) {
+		if err == nil {
+			removed = append(removed, ids[idx])
+		}
+	})
+	w.forgetMedia(removed)
+	if err != nil {
+		return removed, err
+	}
+	if len(removed) < len(ids) {
+		return removed, fmt.Errorf("%d of %d media could not be deleted", len(ids)-len(removed), len(ids))
+	}
+
+	return removed, nil
+}
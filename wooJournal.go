@@ -0,0 +1,301 @@
+package gowoocommerce
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+)
+
+// Type tags of the serializable requests
+const (
+	RequestTypeGet       = "get"
+	RequestTypePost      = "post"
+	RequestTypeBatchPost = "batch_post"
+	RequestTypeDelete    = "delete"
+)
+
+// wooRequestEnvelope is the serialized form of a WooRequest
+type wooRequestEnvelope struct {
+	Type     string          `json:"type"`
+	Endpoint string          `json:"endpoint"`
+	Body     json.RawMessage `json:"body,omitempty"`
+}
+
+// WooRawItem is a WooItem kept as raw JSON, e.g. the items of a request restored from a journal
+type WooRawItem json.RawMessage
+
+// GetID implements WooItem
+func (r WooRawItem) GetID() int32 {
+	var item struct {
+		ID int32 `json:"id"`
+	}
+	json.Unmarshal(r, &item)
+	return item.ID
+}
+
+// MarshalJSON returns the raw JSON unchanged
+func (r WooRawItem) MarshalJSON() ([]byte, error) {
+	if len(r) == 0 {
+		return []byte("null"), nil
+	}
+	return r, nil
+}
+
+// MarshalRequest serializes a WooGetRequest, WooPostRequest, WooBatchPostRequest or WooDeleteRequest including its type tag
+func MarshalRequest(r WooRequest) ([]byte, error) {
+	var env wooRequestEnvelope
+	var err error
+
+	switch req := r.(type) {
+	case WooGetRequest:
+		env = wooRequestEnvelope{Type: RequestTypeGet, Endpoint: req.Endpoint}
+	case WooPostRequest:
+		env = wooRequestEnvelope{Type: RequestTypePost, Endpoint: req.Endpoint}
+		env.Body, err = json.Marshal(req.Payload)
+	case WooBatchPostRequest:
+		env = wooRequestEnvelope{Type: RequestTypeBatchPost, Endpoint: req.Endpoint}
+		env.Body, err = json.Marshal(req)
+	case WooDeleteRequest:
+		env = wooRequestEnvelope{Type: RequestTypeDelete, Endpoint: req.Endpoint}
+	default:
+		return nil, fmt.Errorf("Unable to serialize request of type %T", r)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(env)
+}
+
+// UnmarshalRequest restores a request serialized by MarshalRequest
+// the items of post and batch requests are restored as WooRawItem, so the same body is sent again
+func UnmarshalRequest(b []byte) (WooRequest, error) {
+	var env wooRequestEnvelope
+	err := json.Unmarshal(b, &env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch env.Type {
+	case RequestTypeGet:
+		return WooGetRequest{Endpoint: env.Endpoint}, nil
+	case RequestTypeDelete:
+		return WooDeleteRequest{Endpoint: env.Endpoint}, nil
+	case RequestTypePost:
+		return WooPostRequest{Endpoint: env.Endpoint, Payload: WooRawItem(env.Body)}, nil
+	case RequestTypeBatchPost:
+		var body struct {
+			Create []json.RawMessage `json:"create"`
+			Update []json.RawMessage `json:"update"`
+			Delete []int             `json:"delete"`
+		}
+		err = json.Unmarshal(env.Body, &body)
+		if err != nil {
+			return nil, err
+		}
+		req := WooBatchPostRequest{Endpoint: env.Endpoint, Delete: body.Delete}
+		for i := range body.Create {
+			req.Create = append(req.Create, WooRawItem(body.Create[i]))
+		}
+		for i := range body.Update {
+			req.Update = append(req.Update, WooRawItem(body.Update[i]))
+		}
+		return req, nil
+	}
+
+	return nil, fmt.Errorf("Unknown request type %q", env.Type)
+}
+
+// wooJournalRecord is one line of the journal file: either a queued request or the completion of one
+type wooJournalRecord struct {
+	ID      int64           `json:"id"`
+	Done    bool            `json:"done,omitempty"`
+	Request json.RawMessage `json:"request,omitempty"`
+}
+
+// wooJournal is an append-only JSON lines file recording queued and completed requests
+type wooJournal struct {
+	mu     sync.Mutex
+	file   *os.File
+	nextID int64
+	err    error // first write error, the journal is unusable afterwards
+}
+
+// NewJournaledRequestQueue returns a queue that records every request and its completion in the file at path.
+// If the file exists (e.g. after a crash) all requests that did not complete are loaded back into the queue,
+// so calling Execute resumes the job. Close the queue when the job is done.
+func (w *WooConnection) NewJournaledRequestQueue(path string) (*WooRequestQueue, error) {
+	pending, nextID, err := replayJournal(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// compact: rewrite the file with the pending requests only
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return nil, err
+	}
+	journal := &wooJournal{file: f, nextID: nextID}
+
+	q := w.NewRequestQueue()
+	q.journal = journal
+	for i := range pending {
+		r, err := UnmarshalRequest(pending[i].Request)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("Unable to restore request %d from journal - %v", pending[i].ID, err)
+		}
+		err = journal.write(pending[i])
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		q.requests = append(q.requests, r)
+		q.ids = append(q.ids, pending[i].ID)
+	}
+
+	err = f.Sync()
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return q, nil
+}
+
+// Close closes the journal of the queue, a no-op for queues without journal
+func (q *WooRequestQueue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.journal == nil {
+		return nil
+	}
+	return q.journal.close()
+}
+
+// replayJournal reads the journal and returns the requests without completion in their original order
+func replayJournal(path string) ([]wooJournalRecord, int64, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, 1, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+
+	pending := make(map[int64]wooJournalRecord)
+	var maxID int64
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var rec wooJournalRecord
+		err = json.Unmarshal(scanner.Bytes(), &rec)
+		if err != nil {
+			// a torn last line from a crash while writing: the request was never acknowledged
+			fmt.Printf("Skipping journal line %d - %v\n", line, err)
+			continue
+		}
+		if rec.ID > maxID {
+			maxID = rec.ID
+		}
+		if rec.Done {
+			delete(pending, rec.ID)
+		} else {
+			pending[rec.ID] = rec
+		}
+	}
+	err = scanner.Err()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	records := make([]wooJournalRecord, 0, len(pending))
+	for _, rec := range pending {
+		records = append(records, rec)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].ID < records[j].ID })
+
+	return records, maxID + 1, nil
+}
+
+// add writes a request to the journal and returns its journal ID
+func (j *wooJournal) add(r WooRequest) (int64, error) {
+	b, err := MarshalRequest(r)
+
+	j.mu.Lock()
+	if err != nil {
+		// an unserializable request could never be resumed, so the whole journal fails
+		if j.err == nil {
+			j.err = err
+		}
+		j.mu.Unlock()
+		return 0, err
+	}
+	id := j.nextID
+	j.nextID++
+	j.mu.Unlock()
+
+	return id, j.write(wooJournalRecord{ID: id, Request: b})
+}
+
+// done marks a request as completed
+func (j *wooJournal) done(id int64) error {
+	return j.write(wooJournalRecord{ID: id, Done: true})
+}
+
+func (j *wooJournal) write(rec wooJournalRecord) error {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.err != nil {
+		return j.err
+	}
+	if j.file == nil {
+		return errors.New("Journal is closed")
+	}
+	_, err = j.file.Write(append(b, '\n'))
+	if err == nil {
+		err = j.file.Sync()
+	}
+	if err != nil {
+		j.err = fmt.Errorf("Unable to write journal - %v", err)
+		return j.err
+	}
+	return nil
+}
+
+// failure returns the first write error of the journal
+func (j *wooJournal) failure() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.err
+}
+
+func (j *wooJournal) close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return nil
+	}
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
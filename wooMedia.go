@@ -0,0 +1,249 @@
+package gowoocommerce
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MediaHashMetaKey is the media meta key the SHA-256 of an upload is stored in
+// WordPress only keeps meta keys registered for the REST API and can not filter by them without a query filter, e.g.:
+//
+//	register_post_meta('attachment', 'gowoocommerce_sha256', ['type' => 'string', 'single' => true, 'show_in_rest' => true]);
+//	add_filter('rest_attachment_query', function ($args, $request) {
+//	    if ($hash = $request->get_param('gowoocommerce_sha256')) {
+//	        $args['meta_key'] = 'gowoocommerce_sha256';
+//	        $args['meta_value'] = $hash;
+//	    }
+//	    return $args;
+//	}, 10, 2);
+//
+// The hash is part of the media slug as well, uploads are looked up by the meta key first and by the slug second.
+const MediaHashMetaKey = "gowoocommerce_sha256"
+
+// mediaSlugPrefix prefixes the content hash in the slug of uploaded media
+const mediaSlugPrefix = "gwc-"
+
+// WooMedia is an attachment of the WordPress media library
+type WooMedia struct {
+	ID        int32  `json:"id"`
+	Slug      string `json:"slug,omitempty"`
+	SourceURL string `json:"source_url,omitempty"`
+	AltText   string `json:"alt_text,omitempty"`
+	MimeType  string `json:"mime_type,omitempty"`
+	Post      int32  `json:"post,omitempty"` // the post (e.g. product) the media was uploaded to
+	Title     struct {
+		Rendered string `json:"rendered,omitempty"`
+	} `json:"title,omitempty"`
+	Meta json.RawMessage `json:"meta,omitempty"` // registered meta keys, [] if there are none
+}
+
+// Hash returns the SHA-256 stored in MediaHashMetaKey, "" if it is not registered or not set
+func (m WooMedia) Hash() string {
+	var meta map[string]interface{}
+	if json.Unmarshal(m.Meta, &meta) != nil {
+		return ""
+	}
+	hash, _ := meta[MediaHashMetaKey].(string)
+	return hash
+}
+
+// GetID implements WooItem
+func (m WooMedia) GetID() int32 {
+	return m.ID
+}
+
+// WooMediaOptions describes an upload
+type WooMediaOptions struct {
+	Filename    string // name of the file in the library, required for UploadMedia
+	ContentType string // detected from the file name (or the content) if empty
+	Title       string
+	AltText     string
+	Caption     string
+}
+
+// UploadMediaFile uploads a local file to the media library, see UploadMedia
+func (w *WooConnection) UploadMediaFile(path string, opts WooMediaOptions) (WooMedia, bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return WooMedia{}, false, err
+	}
+	defer f.Close()
+
+	if opts.Filename == "" {
+		opts.Filename = filepath.Base(path)
+	}
+	return w.UploadMedia(f, opts)
+}
+
+// UploadMedia uploads the content of r to /wp-json/wp/v2/media unless the same content was uploaded before
+// Uploads are deduplicated by the SHA-256 of the content; reused is true if an existing attachment was returned.
+// Use the ID with WooProduct.AddMediaImage, WordPress then does not have to download the image itself.
+// The media endpoints need credentials WordPress accepts (e.g. an application password).
+func (w *WooConnection) UploadMedia(r io.Reader, opts WooMediaOptions) (media WooMedia, reused bool, err error) {
+	if w.initialized == false {
+		return media, false, errors.New("Please initialize with your credentials first. WooConnection.Init()")
+	}
+	if opts.Filename == "" {
+		return media, false, errors.New("No file name given")
+	}
+
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return media, false, err
+	}
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+	slug := mediaSlugPrefix + hash[:32]
+
+	media, found, err := w.findMedia(hash, slug)
+	if err != nil || found {
+		return media, found, err
+	}
+
+	if opts.ContentType == "" {
+		opts.ContentType = mime.TypeByExtension(filepath.Ext(opts.Filename))
+	}
+	if opts.ContentType == "" {
+		opts.ContentType = http.DetectContentType(content)
+	}
+	if opts.Title == "" {
+		opts.Title = strings.TrimSuffix(opts.Filename, filepath.Ext(opts.Filename))
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, escapeQuotes(opts.Filename)))
+	header.Set("Content-Type", opts.ContentType)
+	part, err := mw.CreatePart(header)
+	if err != nil {
+		return media, false, err
+	}
+	_, err = part.Write(content)
+	if err != nil {
+		return media, false, err
+	}
+	fields := [][2]string{
+		{"slug", slug},
+		{"title", opts.Title},
+		{"alt_text", opts.AltText},
+		{"caption", opts.Caption},
+		{"meta[" + MediaHashMetaKey + "]", hash},
+	}
+	for _, f := range fields {
+		if f[1] != "" {
+			mw.WriteField(f[0], f[1])
+		}
+	}
+	err = mw.Close()
+	if err != nil {
+		return media, false, err
+	}
+
+	for i := 0; i < w.maxRetries || i == 0; i++ {
+		var resp []byte
+		resp, _, err = w.requestWithContentType("POST", "/wp-json/wp/v2/media", mw.FormDataContentType(), body.Bytes())
+		if err == nil {
+			err = json.Unmarshal(resp, &media)
+			if err == nil && media.ID != 0 {
+				w.cacheMedia(hash, media)
+			}
+			return media, false, err
+		}
+		fmt.Println(err)
+	}
+	return media, false, fmt.Errorf("Error uploading %s - %v", opts.Filename, err)
+}
+
+// findMedia looks for an earlier upload of the same content: in the cache, by the hash meta key, then by slug
+func (w *WooConnection) findMedia(hash, slug string) (WooMedia, bool, error) {
+	w.data.mu.Lock()
+	media, ok := w.data.media[hash]
+	w.data.mu.Unlock()
+	if ok {
+		return media, true, nil
+	}
+
+	// without the query filter the parameter is ignored and the newest media are returned, so the meta is checked
+	var found []WooMedia
+	err := w.getData("/wp-json/wp/v2/media?"+MediaHashMetaKey+"="+hash, &found)
+	if err != nil {
+		return WooMedia{}, false, err
+	}
+	for i := range found {
+		if found[i].Hash() == hash {
+			w.cacheMedia(hash, found[i])
+			return found[i], true, nil
+		}
+	}
+
+	found = nil
+	err = w.getData("/wp-json/wp/v2/media?slug="+url.QueryEscape(slug), &found)
+	if err != nil {
+		return WooMedia{}, false, err
+	}
+	for i := range found {
+		// a slug taken by other content would have a different hash, if the meta key is registered
+		if found[i].Slug == slug && (found[i].Hash() == "" || found[i].Hash() == hash) {
+			w.cacheMedia(hash, found[i])
+			return found[i], true, nil
+		}
+	}
+	return WooMedia{}, false, nil
+}
+
+func (w *WooConnection) cacheMedia(hash string, media WooMedia) {
+	w.data.mu.Lock()
+	defer w.data.mu.Unlock()
+
+	if w.data.media == nil {
+		w.data.media = make(map[string]WooMedia)
+	}
+	w.data.media[hash] = media
+}
+
+// forgetMedia drops deleted media from the cache, so UploadMedia uploads their content again
+func (w *WooConnection) forgetMedia(ids []int32) {
+	deleted := make(map[int32]bool, len(ids))
+	for _, id := range ids {
+		deleted[id] = true
+	}
+
+	w.data.mu.Lock()
+	defer w.data.mu.Unlock()
+
+	for hash, media := range w.data.media {
+		if deleted[media.ID] {
+			delete(w.data.media, hash)
+		}
+	}
+}
+
+// AddMediaImage adds an image from the media library by its ID instead of a URL
+func (p *WooProduct) AddMediaImage(id int32, name string, text string) {
+	p.Images = append(
+		p.Images,
+		WooImage{
+			ID:   id,
+			Name: name,
+			Alt:  text,
+		},
+	)
+}
+
+func escapeQuotes(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
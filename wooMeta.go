@@ -0,0 +1,173 @@
+package gowoocommerce
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// WooMetaEntry is a single custom field
+// An entry with ID and a nil Value is deleted by WooCommerce on update.
+type WooMetaEntry struct {
+	ID    int64       `json:"id,omitempty"` // read-only, set for existing entries
+	Key   string      `json:"key"`
+	Value interface{} `json:"value"`
+}
+
+// WooMetaData is the meta_data list of products, orders and customers
+// Set and Delete keep the IDs of existing entries, so updates edit these entries instead of adding duplicate keys.
+type WooMetaData []WooMetaEntry
+
+// Get returns the value of the first entry with the given key
+func (m WooMetaData) Get(key string) (interface{}, bool) {
+	for i := range m {
+		if m[i].Key == key && m[i].Value != nil {
+			return m[i].Value, true
+		}
+	}
+	return nil, false
+}
+
+// Has reports whether there is a (not deleted) entry with the given key
+func (m WooMetaData) Has(key string) bool {
+	_, ok := m.Get(key)
+	return ok
+}
+
+// GetString returns the value as string, numbers and bools are formatted, objects are returned as JSON
+func (m WooMetaData) GetString(key string) (string, bool) {
+	v, ok := m.Get(key)
+	if ok == false {
+		return "", false
+	}
+	switch val := v.(type) {
+	case string:
+		return val, true
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64), true
+	case bool, int, int32, int64, json.Number:
+		return fmt.Sprint(val), true
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", false
+	}
+	return string(b), true
+}
+
+// GetInt returns the value as integer, numeric strings (WordPress stores all meta as strings) are parsed
+func (m WooMetaData) GetInt(key string) (int64, bool) {
+	s, ok := m.GetString(key)
+	if ok == false {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil || f != float64(int64(f)) {
+			return 0, false
+		}
+		n = int64(f)
+	}
+	return n, true
+}
+
+// GetFloat returns the value as float, numeric strings are parsed
+func (m WooMetaData) GetFloat(key string) (float64, bool) {
+	s, ok := m.GetString(key)
+	if ok == false {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	return f, err == nil
+}
+
+// GetBool returns the value as bool, "1", "yes", "true" and "on" are true
+func (m WooMetaData) GetBool(key string) (bool, bool) {
+	s, ok := m.GetString(key)
+	if ok == false {
+		return false, false
+	}
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "1", "yes", "true", "on":
+		return true, true
+	case "0", "no", "false", "off", "":
+		return false, true
+	}
+	return false, false
+}
+
+// Unmarshal decodes the value into v, e.g. a struct for values stored as object
+// values stored as JSON string are decoded too
+func (m WooMetaData) Unmarshal(key string, v interface{}) error {
+	val, ok := m.Get(key)
+	if ok == false {
+		return fmt.Errorf("Meta key %q not found", key)
+	}
+	if s, ok := val.(string); ok && json.Valid([]byte(s)) {
+		return json.Unmarshal([]byte(s), v)
+	}
+	b, err := json.Marshal(val)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// Set sets the value of the key, an existing entry is edited by its ID and further entries with the same key are deleted
+func (m *WooMetaData) Set(key string, value interface{}) {
+	found := false
+	result := make(WooMetaData, 0, len(*m)) // never reuse the backing array, copies of a product share it
+	for _, entry := range *m {
+		if entry.Key != key {
+			result = append(result, entry)
+			continue
+		}
+		if found == false {
+			entry.Value = value
+			found = true
+			result = append(result, entry)
+			continue
+		}
+		// duplicate key: existing entries have to be deleted explicitly, new ones are just dropped
+		if entry.ID != 0 {
+			entry.Value = nil
+			result = append(result, entry)
+		}
+	}
+	if found == false {
+		result = append(result, WooMetaEntry{Key: key, Value: value})
+	}
+	*m = result
+}
+
+// Delete removes all entries of the key, existing entries are kept with a nil value so the update deletes them
+func (m *WooMetaData) Delete(key string) {
+	result := make(WooMetaData, 0, len(*m))
+	for _, entry := range *m {
+		if entry.Key != key {
+			result = append(result, entry)
+			continue
+		}
+		if entry.ID != 0 {
+			entry.Value = nil
+			result = append(result, entry)
+		}
+	}
+	*m = result
+}
+
+// Keys returns the keys of all (not deleted) entries in order, each key once
+func (m WooMetaData) Keys() []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for i := range m {
+		if m[i].Value == nil || seen[m[i].Key] {
+			continue
+		}
+		seen[m[i].Key] = true
+		keys = append(keys, m[i].Key)
+	}
+	return keys
+}
@@ -0,0 +1,398 @@
+package gowoocommerce
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// moneyDecimals is the precision WooMoney calculates with, more than any currency uses
+const moneyDecimals = 6
+
+var moneyScale = pow10(moneyDecimals)
+
+// maxMicros and minMicros mark amounts out of range, results beyond them saturate instead of wrapping around
+const (
+	maxMicros = math.MaxInt64
+	minMicros = -math.MaxInt64
+)
+
+// WooMoney is an exact decimal amount for price calculations, the zero value is 0
+// Amounts are limited to about ±9.2 trillion (int64 millionths). A result out of range is kept as
+// overflow through all further calculations, see Overflowed; MarshalJSON refuses to write it.
+type WooMoney struct {
+	micros int64 // amount in millionths
+}
+
+// NewMoney returns units * 10^-decimals, e.g. NewMoney(1999, 2) is 19.99
+func NewMoney(units int64, decimals int) WooMoney {
+	if decimals > moneyDecimals {
+		return saturate(roundDiv(big.NewInt(units), bigPow10(decimals-moneyDecimals)))
+	}
+	return saturate(new(big.Int).Mul(big.NewInt(units), bigPow10(moneyDecimals-decimals)))
+}
+
+// ParseMoney parses a decimal number like "12", "-0.5" or "19.99", more than 6 decimals are rounded
+func ParseMoney(s string) (WooMoney, error) {
+	s = strings.TrimSpace(s)
+	str := s
+	negative := false
+	if strings.HasPrefix(str, "-") || strings.HasPrefix(str, "+") {
+		negative = str[0] == '-'
+		str = str[1:]
+	}
+	intPart, fracPart, _ := strings.Cut(str, ".")
+	if intPart == "" && fracPart == "" || strings.Trim(intPart+fracPart, "0123456789") != "" {
+		return WooMoney{}, fmt.Errorf("Invalid amount %q", s)
+	}
+
+	units, ok := new(big.Int).SetString("0"+intPart+fracPart, 10)
+	if ok == false {
+		return WooMoney{}, fmt.Errorf("Invalid amount %q", s)
+	}
+	if negative {
+		units.Neg(units)
+	}
+
+	if len(fracPart) > moneyDecimals {
+		units = roundDiv(units, bigPow10(len(fracPart)-moneyDecimals))
+	} else {
+		units.Mul(units, bigPow10(moneyDecimals-len(fracPart)))
+	}
+	m := saturate(units)
+	if m.Overflowed() {
+		return WooMoney{}, fmt.Errorf("Amount %q out of range", s)
+	}
+	return m, nil
+}
+
+// Overflowed reports whether m is the result of a calculation out of range, see WooMoney
+func (m WooMoney) Overflowed() bool {
+	return m.micros >= maxMicros || m.micros <= minMicros
+}
+
+// Add returns m + o
+func (m WooMoney) Add(o WooMoney) WooMoney {
+	if m.Overflowed() {
+		return m
+	}
+	if o.Overflowed() {
+		return o
+	}
+	return saturate(new(big.Int).Add(big.NewInt(m.micros), big.NewInt(o.micros)))
+}
+
+// Sub returns m - o
+func (m WooMoney) Sub(o WooMoney) WooMoney {
+	return m.Add(o.Neg())
+}
+
+// Neg returns -m
+func (m WooMoney) Neg() WooMoney {
+	return WooMoney{-m.micros}
+}
+
+// MulInt returns m * n, e.g. the total of n items
+func (m WooMoney) MulInt(n int64) WooMoney {
+	if m.Overflowed() {
+		return m
+	}
+	return saturate(new(big.Int).Mul(big.NewInt(m.micros), big.NewInt(n)))
+}
+
+// Mul returns m * factor, e.g. m.Mul(exchangeRate)
+func (m WooMoney) Mul(factor WooMoney) WooMoney {
+	if m.Overflowed() {
+		return m
+	}
+	if factor.Overflowed() {
+		return factor
+	}
+	product := new(big.Int).Mul(big.NewInt(m.micros), big.NewInt(factor.micros))
+	return saturate(roundDiv(product, big.NewInt(moneyScale)))
+}
+
+// Div returns m / divisor, Div by zero returns 0
+func (m WooMoney) Div(divisor WooMoney) WooMoney {
+	if m.Overflowed() {
+		return m
+	}
+	if divisor.Overflowed() {
+		return divisor
+	}
+	if divisor.micros == 0 {
+		return WooMoney{}
+	}
+	dividend := new(big.Int).Mul(big.NewInt(m.micros), big.NewInt(moneyScale))
+	return saturate(roundDiv(dividend, big.NewInt(divisor.micros)))
+}
+
+// Percent returns pct percent of m, e.g. m.Percent(NewMoney(19, 0)) for 19% VAT
+func (m WooMoney) Percent(pct WooMoney) WooMoney {
+	return m.Mul(pct).Div(NewMoney(100, 0))
+}
+
+// Markup returns m increased by pct percent, negative values give a discount
+func (m WooMoney) Markup(pct WooMoney) WooMoney {
+	return m.Add(m.Percent(pct))
+}
+
+// Round rounds half away from zero to the given number of decimals
+func (m WooMoney) Round(decimals int) WooMoney {
+	if decimals >= moneyDecimals || m.Overflowed() {
+		return m
+	}
+	if decimals < 0 {
+		decimals = 0
+	}
+	step := bigPow10(moneyDecimals - decimals)
+	q := roundDiv(big.NewInt(m.micros), step)
+	return saturate(q.Mul(q, step))
+}
+
+// Cmp returns -1, 0 or +1 if m is less than, equal to or greater than o
+func (m WooMoney) Cmp(o WooMoney) int {
+	switch {
+	case m.micros < o.micros:
+		return -1
+	case m.micros > o.micros:
+		return 1
+	}
+	return 0
+}
+
+// IsZero reports whether m is 0
+func (m WooMoney) IsZero() bool {
+	return m.micros == 0
+}
+
+// Sign returns -1, 0 or +1
+func (m WooMoney) Sign() int {
+	return m.Cmp(WooMoney{})
+}
+
+// Format returns m rounded to exactly the given number of decimals, e.g. "19.90"
+func (m WooMoney) Format(decimals int) string {
+	if decimals > moneyDecimals {
+		decimals = moneyDecimals
+	}
+	if decimals < 0 {
+		decimals = 0
+	}
+	r := m.Round(decimals).micros
+	sign := ""
+	if r < 0 {
+		sign = "-"
+		r = -r
+	}
+	s := fmt.Sprintf("%s%d", sign, r/moneyScale)
+	if decimals > 0 {
+		frac := fmt.Sprintf("%0*d", moneyDecimals, r%moneyScale)
+		s += "." + frac[:decimals]
+	}
+	return s
+}
+
+// String returns m without trailing zeros, e.g. "19.9"
+func (m WooMoney) String() string {
+	s := m.Format(moneyDecimals)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// Price returns m as WooPrice with the given number of decimals
+func (m WooMoney) Price(decimals int) WooPrice {
+	return WooPrice(m.Format(decimals))
+}
+
+// MarshalJSON writes the amount as string like WooCommerce does, an overflow is an error
+func (m WooMoney) MarshalJSON() ([]byte, error) {
+	if m.Overflowed() {
+		return nil, errors.New("Amount out of range")
+	}
+	return json.Marshal(m.String())
+}
+
+// UnmarshalJSON reads string and number amounts
+func (m *WooMoney) UnmarshalJSON(b []byte) error {
+	var p WooPrice
+	err := p.UnmarshalJSON(b)
+	if err != nil {
+		return err
+	}
+	if p == "" {
+		*m = WooMoney{}
+		return nil
+	}
+	*m, err = ParseMoney(string(p))
+	return err
+}
+
+// WooPrice is a price as the REST API sends it, a decimal string with "." as separator or "" if not set
+// It reads both string and number JSON, e.g. WPML's custom prices are often numbers.
+type WooPrice string
+
+// IsSet reports whether the price is not empty
+func (p WooPrice) IsSet() bool {
+	return strings.TrimSpace(string(p)) != ""
+}
+
+// Money parses the price for calculations, an empty price is an error
+func (p WooPrice) Money() (WooMoney, error) {
+	if p.IsSet() == false {
+		return WooMoney{}, errors.New("Price is not set")
+	}
+	return ParseMoney(string(p))
+}
+
+// UnmarshalJSON reads strings, numbers and null
+func (p *WooPrice) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	if bytes.Equal(b, []byte("null")) {
+		*p = ""
+		return nil
+	}
+	if len(b) > 0 && b[0] == '"' {
+		var s string
+		err := json.Unmarshal(b, &s)
+		*p = WooPrice(s)
+		return err
+	}
+	var n json.Number
+	err := json.Unmarshal(b, &n)
+	if err != nil {
+		return fmt.Errorf("Invalid price %s", b)
+	}
+	// numbers like 1e2 are normalized to plain decimals
+	if strings.ContainsAny(string(n), "eE") {
+		f, err := n.Float64()
+		if err != nil {
+			return err
+		}
+		n = json.Number(strconv.FormatFloat(f, 'f', -1, 64))
+	}
+	*p = WooPrice(n)
+	return nil
+}
+
+// WooPriceFormat holds the shop's price settings (WooCommerce > Settings > General)
+type WooPriceFormat struct {
+	Currency          string
+	CurrencyPosition  string // Options: left, right, left_space, right_space
+	DecimalSeparator  string
+	ThousandSeparator string
+	Decimals          int
+}
+
+// Price rounds m to the shop's decimals and returns it in the form the REST API expects
+func (f WooPriceFormat) Price(m WooMoney) WooPrice {
+	return m.Price(f.Decimals)
+}
+
+// Display formats m with the shop's separators for humans, e.g. "1.234,50", without currency symbol
+func (f WooPriceFormat) Display(m WooMoney) string {
+	s := m.Format(f.Decimals)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	intPart, fracPart, hasFrac := strings.Cut(s, ".")
+
+	var grouped strings.Builder
+	for i := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			grouped.WriteString(f.ThousandSeparator)
+		}
+		grouped.WriteByte(intPart[i])
+	}
+	if hasFrac {
+		return sign + grouped.String() + f.DecimalSeparator + fracPart
+	}
+	return sign + grouped.String()
+}
+
+// GetPriceFormat returns the shop's price settings
+// https://woocommerce.github.io/woocommerce-rest-api-docs/#retrieve-a-setting-option
+func (w *WooConnection) GetPriceFormat() (WooPriceFormat, error) {
+	w.data.mu.Lock()
+	cached := w.data.priceFormat
+	w.data.mu.Unlock()
+	if cached != nil {
+		return *cached, nil
+	}
+
+	var options []struct {
+		ID    string      `json:"id"`
+		Value interface{} `json:"value"`
+	}
+	err := w.getData("/wp-json/wc/v3/settings/general", &options)
+	if err != nil {
+		return WooPriceFormat{}, err
+	}
+
+	format := WooPriceFormat{DecimalSeparator: ".", ThousandSeparator: ",", Decimals: 2}
+	for _, o := range options {
+		value := fmt.Sprint(o.Value)
+		switch o.ID {
+		case "woocommerce_currency":
+			format.Currency = value
+		case "woocommerce_currency_pos":
+			format.CurrencyPosition = value
+		case "woocommerce_price_decimal_sep":
+			format.DecimalSeparator = value
+		case "woocommerce_price_thousand_sep":
+			format.ThousandSeparator = value
+		case "woocommerce_price_num_decimals":
+			if n, err := strconv.Atoi(value); err == nil {
+				format.Decimals = n
+			}
+		}
+	}
+	w.data.mu.Lock()
+	w.data.priceFormat = &format
+	w.data.mu.Unlock()
+
+	return format, nil
+}
+
+// roundDiv returns n / d rounded half away from zero
+func roundDiv(n, d *big.Int) *big.Int {
+	q, r := new(big.Int).QuoRem(n, d, new(big.Int))
+	r.Abs(r).Mul(r, big.NewInt(2))
+	if r.Cmp(new(big.Int).Abs(d)) >= 0 {
+		if n.Sign() != d.Sign() {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return q
+}
+
+// saturate returns n millionths, amounts out of range become the overflow markers
+func saturate(n *big.Int) WooMoney {
+	switch {
+	case n.Cmp(big.NewInt(maxMicros)) >= 0:
+		return WooMoney{maxMicros}
+	case n.Cmp(big.NewInt(minMicros)) <= 0:
+		return WooMoney{minMicros}
+	}
+	return WooMoney{n.Int64()}
+}
+
+func bigPow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+func pow10(n int) int64 {
+	p := int64(1)
+	for i := 0; i < n; i++ {
+		p *= 10
+	}
+	return p
+}
@@ -0,0 +1,127 @@
+package gowoocommerce
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		err  bool
+	}{
+		{"12", "12", false},
+		{" 19.99 ", "19.99", false},
+		{"-0.5", "-0.5", false},
+		{"+3.10", "3.1", false},
+		{".5", "0.5", false},
+		{"5.", "5", false},
+		{"0.0000005", "0.000001", false},   // half away from zero
+		{"-0.0000005", "-0.000001", false}, // half away from zero
+		{"0.00000049", "0", false},
+		{"1.0000000000000000000000001", "1", false},
+		{"9223372036854.775806", "9223372036854.775806", false},
+		{"9223372036854.775807", "", true},
+		{"-9223372036854.775807", "", true},
+		{"100000000000000000000", "", true},
+		{"", "", true},
+		{".", "", true},
+		{"1.2.3", "", true},
+		{"1,5", "", true},
+		{"abc", "", true},
+		{"--1", "", true},
+	}
+	for _, tt := range tests {
+		m, err := ParseMoney(tt.in)
+		if (err != nil) != tt.err {
+			t.Errorf("ParseMoney(%q) error = %v, want error %v", tt.in, err, tt.err)
+			continue
+		}
+		if err == nil && m.String() != tt.want {
+			t.Errorf("ParseMoney(%q) = %s, want %s", tt.in, m, tt.want)
+		}
+	}
+}
+
+func TestRoundDiv(t *testing.T) {
+	tests := []struct {
+		n, d, want int64
+	}{
+		{10, 4, 3}, // 2.5
+		{-10, 4, -3},
+		{10, -4, -3},
+		{-10, -4, 3},
+		{9, 4, 2}, // 2.25
+		{-9, 4, -2},
+		{11, 4, 3}, // 2.75
+		{0, 7, 0},
+		{6, 3, 2},
+	}
+	for _, tt := range tests {
+		if got := roundDiv(big.NewInt(tt.n), big.NewInt(tt.d)).Int64(); got != tt.want {
+			t.Errorf("roundDiv(%d, %d) = %d, want %d", tt.n, tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	tests := []struct {
+		name string
+		got  WooMoney
+		want string
+	}{
+		{"NewMoney", NewMoney(1999, 2), "19.99"},
+		{"NewMoney rounds", NewMoney(15, 7), "0.000002"},
+		{"Add", NewMoney(1, 1).Add(NewMoney(2, 1)), "0.3"},
+		{"Sub", NewMoney(1, 0).Sub(NewMoney(1, 2)), "0.99"},
+		{"MulInt", NewMoney(1999, 2).MulInt(3), "59.97"},
+		{"Mul", NewMoney(1999, 2).Mul(NewMoney(108, 2)), "21.5892"},
+		{"Div", NewMoney(1, 0).Div(NewMoney(3, 0)), "0.333333"},
+		{"Div by zero", NewMoney(1, 0).Div(WooMoney{}), "0"},
+		{"Percent", NewMoney(1999, 2).Percent(NewMoney(19, 0)), "3.7981"},
+		{"Markup", NewMoney(100, 0).Markup(NewMoney(-25, 0)), "75"},
+		{"Round half up", NewMoney(1005, 3).Round(2), "1.01"},
+		{"Round half down", NewMoney(-1005, 3).Round(2), "-1.01"},
+		{"Round to integer", NewMoney(25, 1).Round(0), "3"},
+	}
+	for _, tt := range tests {
+		if tt.got.String() != tt.want {
+			t.Errorf("%s = %s, want %s", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestMoneyOverflow(t *testing.T) {
+	large, err := ParseMoney("9000000000000")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		got  WooMoney
+	}{
+		{"Add", large.Add(large)},
+		{"Sub", large.Neg().Sub(large)},
+		{"MulInt", large.MulInt(2)},
+		{"Mul", large.Mul(NewMoney(2, 0))},
+		{"Div", large.Div(NewMoney(1, 2))},
+		{"NewMoney", NewMoney(1<<62, 0)},
+		{"sticky", large.Add(large).Sub(large).Sub(large)},
+		{"roundToStep", roundToStep(NewMoney(9223372036854775, 3), NewMoney(1000000, 0), RoundUp)},
+	}
+	for _, tt := range tests {
+		if tt.got.Overflowed() == false {
+			t.Errorf("%s = %s, want overflow", tt.name, tt.got)
+		}
+	}
+
+	if large.Overflowed() || large.Add(NewMoney(1, 0)).Overflowed() {
+		t.Error("amounts in range must not overflow")
+	}
+	if _, err := json.Marshal(large.MulInt(2)); err == nil {
+		t.Error("marshalling an overflow must fail")
+	}
+}
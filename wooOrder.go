@@ -0,0 +1,64 @@
+package gowoocommerce
+
+// WooAddress is the billing or shipping address of orders and customers
+type WooAddress struct {
+	FirstName string `json:"first_name,omitempty"`
+	LastName  string `json:"last_name,omitempty"`
+	Company   string `json:"company,omitempty"`
+	Address1  string `json:"address_1,omitempty"`
+	Address2  string `json:"address_2,omitempty"`
+	City      string `json:"city,omitempty"`
+	State     string `json:"state,omitempty"` // ISO code or name of the state, see GetCountries
+	Postcode  string `json:"postcode,omitempty"`
+	Country   string `json:"country,omitempty"` // ISO code of the country
+	Email     string `json:"email,omitempty"`   // billing only
+	Phone     string `json:"phone,omitempty"`
+}
+
+// WooOrderLineItem is a product line of an order
+type WooOrderLineItem struct {
+	ID          int32       `json:"id,omitempty"`
+	Name        string      `json:"name,omitempty"`
+	ProductID   int32       `json:"product_id,omitempty"`
+	VariationID int32       `json:"variation_id,omitempty"`
+	Quantity    int32       `json:"quantity,omitempty"`
+	TaxClass    string      `json:"tax_class,omitempty"`
+	Subtotal    string      `json:"subtotal,omitempty"`
+	Total       string      `json:"total,omitempty"`
+	TotalTax    string      `json:"total_tax,omitempty"`
+	SKU         string      `json:"sku,omitempty"`   // read-only
+	Price       float64     `json:"price,omitempty"` // read-only
+	MetaData    WooMetaData `json:"meta_data,omitempty"`
+}
+
+// WooOrder holds the commonly used fields of an order
+type WooOrder struct {
+	ID                 int32              `json:"id,omitempty"` // read-only
+	ParentID           int32              `json:"parent_id,omitempty"`
+	Number             string             `json:"number,omitempty"`    // read-only
+	OrderKey           string             `json:"order_key,omitempty"` // read-only
+	Status             string             `json:"status,omitempty"`    // Options: pending, processing, on-hold, completed, cancelled, refunded, failed and trash
+	Currency           string             `json:"currency,omitempty"`
+	DateCreatedGmt     WooTime            `json:"date_created_gmt,omitzero"`  // read-only
+	DateModifiedGmt    WooTime            `json:"date_modified_gmt,omitzero"` // read-only
+	DiscountTotal      string             `json:"discount_total,omitempty"`   // read-only
+	ShippingTotal      string             `json:"shipping_total,omitempty"`   // read-only
+	Total              string             `json:"total,omitempty"`            // read-only
+	TotalTax           string             `json:"total_tax,omitempty"`        // read-only
+	CustomerID         int32              `json:"customer_id,omitempty"`
+	CustomerNote       string             `json:"customer_note,omitempty"`
+	Billing            WooAddress         `json:"billing,omitempty"`
+	Shipping           WooAddress         `json:"shipping,omitempty"`
+	PaymentMethod      string             `json:"payment_method,omitempty"`
+	PaymentMethodTitle string             `json:"payment_method_title,omitempty"`
+	TransactionID      string             `json:"transaction_id,omitempty"`
+	DatePaidGmt        WooTime            `json:"date_paid_gmt,omitzero"`      // read-only
+	DateCompletedGmt   WooTime            `json:"date_completed_gmt,omitzero"` // read-only
+	LineItems          []WooOrderLineItem `json:"line_items,omitempty"`
+	MetaData           WooMetaData        `json:"meta_data,omitempty"`
+}
+
+// GetID implements WooItem
+func (o WooOrder) GetID() int32 {
+	return o.ID
+}
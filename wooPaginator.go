@@ -0,0 +1,204 @@
+package gowoocommerce
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// defaultPageSize is the maximum per_page value WooCommerce accepts
+const defaultPageSize = 100
+
+// wooPage is the result of a single page request
+type wooPage struct {
+	body   []byte
+	header http.Header
+	err    error
+}
+
+// Paginate lazily streams all items of a list endpoint, e.g. "/wp-json/wc/v3/products?status=publish"
+// The total number of pages is taken from the X-WP-TotalPages header and up to maxConcurrentRequests
+// pages are prefetched in parallel. Without that header the rel="next" Link header is followed page by page.
+// Only the prefetched pages are held in memory, so arbitrarily large catalogs can be processed.
+// perPage <= 0 uses the maximum page size of 100.
+func Paginate[T any](w *WooConnection, endpoint string, perPage int) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+
+		if w.initialized == false {
+			yield(zero, errors.New("Please initialize with your credentials first. WooConnection.Init()"))
+			return
+		}
+
+		if perPage <= 0 || perPage > defaultPageSize {
+			perPage = defaultPageSize
+		}
+		endpoint = setQueryParam(endpoint, "per_page", strconv.Itoa(perPage))
+
+		first := w.fetchPage(setQueryParam(endpoint, "page", "1"))
+		if first.err != nil {
+			yield(zero, first.err)
+			return
+		}
+		if emitPage(w, endpoint, first.body, yield) == false {
+			return
+		}
+
+		totalPages, err := strconv.Atoi(first.header.Get("X-WP-TotalPages"))
+		if err != nil {
+			// no page count available: walk the Link headers
+			next := w.nextLink(first.header)
+			for next != "" {
+				page := w.fetchPage(next)
+				if page.err != nil {
+					yield(zero, page.err)
+					return
+				}
+				if emitPage(w, endpoint, page.body, yield) == false {
+					return
+				}
+				next = w.nextLink(page.header)
+			}
+			return
+		}
+
+		prefetch := w.maxConcurrentRequests
+		if prefetch < 1 {
+			prefetch = 1
+		}
+
+		// every page gets its own buffered channel so abandoned fetches never block
+		pending := make([]chan wooPage, 0, prefetch)
+		nextPage := 2
+		schedule := func() {
+			for len(pending) < prefetch && nextPage <= totalPages {
+				ch := make(chan wooPage, 1)
+				go func(ep string) {
+					ch <- w.fetchPage(ep)
+				}(setQueryParam(endpoint, "page", strconv.Itoa(nextPage)))
+				pending = append(pending, ch)
+				nextPage++
+			}
+		}
+
+		schedule()
+		for len(pending) > 0 {
+			page := <-pending[0]
+			pending = pending[1:]
+			if page.err != nil {
+				yield(zero, page.err)
+				return
+			}
+			schedule()
+			if emitPage(w, endpoint, page.body, yield) == false {
+				return
+			}
+		}
+	}
+}
+
+// emitPage decodes a page and yields its items one by one; returns false once the consumer stopped
+// fields that can not be decoded are recorded in the DecodeReport, in strict mode they stop the iteration
+func emitPage[T any](w *WooConnection, endpoint string, body []byte, yield func(T, error) bool) bool {
+	var zero T
+	items, errs, err := decodeList[T](body)
+	if err != nil {
+		yield(zero, fmt.Errorf("Unable to parse page - %v", err))
+		return false
+	}
+	if len(errs) > 0 && w.strictDecoding() {
+		errs[0].Endpoint = endpoint
+		yield(zero, errs[0])
+		return false
+	}
+	w.recordDecodeErrors(endpoint, errs)
+
+	for i := range items {
+		if yield(items[i], nil) == false {
+			return false
+		}
+	}
+	return true
+}
+
+// CollectAll drains a paginated sequence into a slice and stops at the first error
+func CollectAll[T any](seq iter.Seq2[T, error]) ([]T, error) {
+	var items []T
+	for item, err := range seq {
+		if err != nil {
+			return items, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// fetchPage sends a GET request (with retries) and keeps the response header for the pagination
+func (w *WooConnection) fetchPage(endpoint string) wooPage {
+	var page wooPage
+	for i := 0; i < w.maxRetries || i == 0; i++ {
+		page.body, page.header, page.err = w.requestWithHeader("GET", endpoint, nil)
+		if page.err == nil {
+			return page
+		}
+		fmt.Println(page.err)
+	}
+	page.err = fmt.Errorf("Error sending request - %v", page.err)
+	return page
+}
+
+// nextLink returns the endpoint referenced as rel="next" in the Link header, empty if there is none
+// e.g.: Link: <https://example.com/wp-json/wc/v3/products?page=2>; rel="next"
+func (w *WooConnection) nextLink(header http.Header) string {
+	for _, link := range header.Values("Link") {
+		for _, part := range strings.Split(link, ",") {
+			segments := strings.Split(part, ";")
+			if len(segments) < 2 || strings.Contains(segments[1], `rel="next"`) == false {
+				continue
+			}
+			href := strings.Trim(strings.TrimSpace(segments[0]), "<>")
+
+			u, err := url.Parse(href)
+			if err != nil {
+				return ""
+			}
+			// the credentials are added again by buildLink
+			q := u.Query()
+			q.Del("consumer_key")
+			q.Del("consumer_secret")
+			u.RawQuery = q.Encode()
+
+			return strings.TrimPrefix(u.RequestURI(), strings.TrimSuffix(w.basePath(), "/"))
+		}
+	}
+	return ""
+}
+
+// basePath returns the path component of the configured domain (e.g. for shops in a subdirectory)
+func (w *WooConnection) basePath() string {
+	u, err := url.Parse(w.credentials.domain)
+	if err != nil {
+		return ""
+	}
+	return u.Path
+}
+
+// setQueryParam sets (or replaces) a query parameter of an endpoint
+func setQueryParam(endpoint, key, value string) string {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		sep := "?"
+		if strings.Contains(endpoint, "?") {
+			sep = "&"
+		}
+		return endpoint + sep + url.QueryEscape(key) + "=" + url.QueryEscape(value)
+	}
+	q := u.Query()
+	q.Set(key, value)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
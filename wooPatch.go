@@ -0,0 +1,181 @@
+package gowoocommerce
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// WooProductPatch is a partial product update that sends exactly the fields set, zero values included
+// WooProduct marshals with omitempty, so e.g. a stock quantity of 0 or featured=false would be dropped.
+type WooProductPatch struct {
+	ID     int32
+	fields map[string]interface{}
+}
+
+// NewProductPatch returns an empty patch for the product with the given ID
+func NewProductPatch(id int32) *WooProductPatch {
+	return &WooProductPatch{ID: id, fields: make(map[string]interface{})}
+}
+
+// PatchFromProduct returns a patch with the given JSON fields of p (field mask), zero values included
+// e.g. PatchFromProduct(p, "stock_quantity", "featured") sends both fields even if they are 0 / false
+func PatchFromProduct(p WooProduct, fields ...string) (*WooProductPatch, error) {
+	patch := NewProductPatch(p.GetID())
+	values := productFieldValues(p)
+	for _, field := range fields {
+		if field == "id" || readOnlyProductFields[field] {
+			return nil, fmt.Errorf("Field %q is read-only", field)
+		}
+		value, ok := values[field]
+		if ok == false {
+			return nil, fmt.Errorf("Unknown product field %q", field)
+		}
+		patch.Set(field, value)
+	}
+	return patch, nil
+}
+
+// Set sets a field by its JSON name, nil is sent as null
+func (p *WooProductPatch) Set(field string, value interface{}) *WooProductPatch {
+	if p.fields == nil {
+		p.fields = make(map[string]interface{})
+	}
+	if t, ok := value.(WooTime); ok && strings.HasSuffix(field, "_gmt") {
+		value = gmtTime(t)
+	}
+	p.fields[field] = value
+	return p
+}
+
+// Unset removes a field from the patch, it is not sent at all
+func (p *WooProductPatch) Unset(field string) *WooProductPatch {
+	delete(p.fields, field)
+	return p
+}
+
+// Fields returns the JSON names of the fields set
+func (p *WooProductPatch) Fields() []string {
+	return sortedKeys(p.fields)
+}
+
+// SetName sets the product name
+func (p *WooProductPatch) SetName(name string) *WooProductPatch {
+	return p.Set("name", name)
+}
+
+// SetStatus sets the status, e.g. draft, pending, private or publish
+func (p *WooProductPatch) SetStatus(status string) *WooProductPatch {
+	return p.Set("status", status)
+}
+
+// SetFeatured sets the featured flag, false is sent too
+func (p *WooProductPatch) SetFeatured(featured bool) *WooProductPatch {
+	return p.Set("featured", featured)
+}
+
+// SetRegularPrice sets the regular price, "" clears it
+func (p *WooProductPatch) SetRegularPrice(price WooPrice) *WooProductPatch {
+	return p.Set("regular_price", price)
+}
+
+// SetSalePrice sets the sale price, "" clears it
+func (p *WooProductPatch) SetSalePrice(price WooPrice) *WooProductPatch {
+	return p.Set("sale_price", price)
+}
+
+// ClearSale removes the sale price and the sale schedule
+func (p *WooProductPatch) ClearSale() *WooProductPatch {
+	p.Set("sale_price", "")
+	p.Set("date_on_sale_from", nil)
+	p.Set("date_on_sale_from_gmt", nil)
+	p.Set("date_on_sale_to", nil)
+	return p.Set("date_on_sale_to_gmt", nil)
+}
+
+// SetManageStock enables or disables stock management
+func (p *WooProductPatch) SetManageStock(manage bool) *WooProductPatch {
+	return p.Set("manage_stock", manage)
+}
+
+// SetStockQuantity sets the stock quantity, 0 is sent too (stock management must be enabled)
+func (p *WooProductPatch) SetStockQuantity(quantity int32) *WooProductPatch {
+	return p.Set("stock_quantity", quantity)
+}
+
+// SetStockStatus sets the stock status: instock, outofstock or onbackorder
+func (p *WooProductPatch) SetStockStatus(status string) *WooProductPatch {
+	return p.Set("stock_status", status)
+}
+
+// SetReviewsAllowed allows or disallows reviews, false is sent too
+func (p *WooProductPatch) SetReviewsAllowed(allowed bool) *WooProductPatch {
+	return p.Set("reviews_allowed", allowed)
+}
+
+// SetSoldIndividually sets whether only one item can be bought per order
+func (p *WooProductPatch) SetSoldIndividually(individually bool) *WooProductPatch {
+	return p.Set("sold_individually", individually)
+}
+
+// SetMenuOrder sets the menu order, 0 is sent too
+func (p *WooProductPatch) SetMenuOrder(order int32) *WooProductPatch {
+	return p.Set("menu_order", order)
+}
+
+// GetID implements WooItem
+func (p *WooProductPatch) GetID() int32 {
+	return p.ID
+}
+
+// MarshalJSON returns the ID and exactly the fields set
+func (p *WooProductPatch) MarshalJSON() ([]byte, error) {
+	body := make(map[string]interface{}, len(p.fields)+1)
+	for field, value := range p.fields {
+		body[field] = value
+	}
+	if p.ID != 0 {
+		body["id"] = p.ID
+	}
+	return json.Marshal(body)
+}
+
+// PatchProducts sends the patches as batch updates
+func (w *WooConnection) PatchProducts(patches []*WooProductPatch, verbose bool) (WooBatchResponse, error) {
+	items := make([]WooItem, len(patches))
+	for i := range patches {
+		if patches[i].ID == 0 {
+			return WooBatchResponse{}, fmt.Errorf("Patch %d has no product ID", i)
+		}
+		items[i] = patches[i]
+	}
+	return w.BatchUpdate("/wp-json/wc/v3/products/batch", items, verbose)
+}
+
+// PatchVariations sends the patches as batch updates of the variations of a variable product
+func (w *WooConnection) PatchVariations(productID int32, patches []*WooProductPatch, verbose bool) (WooBatchResponse, error) {
+	items := make([]WooItem, len(patches))
+	for i := range patches {
+		if patches[i].ID == 0 {
+			return WooBatchResponse{}, fmt.Errorf("Patch %d has no variation ID", i)
+		}
+		items[i] = patches[i]
+	}
+	return w.BatchUpdate(fmt.Sprintf("/wp-json/wc/v3/products/%d/variations/batch", productID), items, verbose)
+}
+
+// productFieldValues maps the JSON names of the WooProduct fields to their values, zero values included
+func productFieldValues(p WooProduct) map[string]interface{} {
+	values := make(map[string]interface{})
+	v := reflect.ValueOf(p)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		values[name] = v.Field(i).Interface()
+	}
+	return values
+}
@@ -0,0 +1,240 @@
+package gowoocommerce
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// productEnumFields are the enum fields checked before product requests are sent
+var productEnumFields = map[string][]string{
+	"type":               productTypes,
+	"status":             productStatuses,
+	"stock_status":       stockStatuses,
+	"catalog_visibility": catalogVisibilities,
+	"tax_status":         taxStatuses,
+}
+
+// WooRequestValidationError lists everything wrong with a single request
+type WooRequestValidationError struct {
+	Endpoint string
+	Problems []string
+}
+
+func (e *WooRequestValidationError) Error() string {
+	return fmt.Sprintf("Invalid request to %s: %s", e.Endpoint, strings.Join(e.Problems, "; "))
+}
+
+// WooPreflightError is returned by Execute when queued requests failed the pre-flight validation
+type WooPreflightError struct {
+	Invalid  map[int]*WooRequestValidationError // by position in the queue
+	Rejected map[int]WooRequest                 // the invalid requests, by position in the queue
+}
+
+func (e *WooPreflightError) Error() string {
+	idx := e.Positions()
+	return fmt.Sprintf("%d requests failed the pre-flight validation, first: request %d - %v", len(idx), idx[0], e.Invalid[idx[0]])
+}
+
+// Positions returns the positions of the invalid requests in ascending order, e.g. for WooRequestQueue.Remove
+func (e *WooPreflightError) Positions() []int {
+	idx := make([]int, 0, len(e.Invalid))
+	for i := range e.Invalid {
+		idx = append(idx, i)
+	}
+	sort.Ints(idx)
+	return idx
+}
+
+// SetPreflightValidation enables or disables the validation of queued requests before they are sent
+// strict Execute calls reject the whole queue before any network call (the requests stay queued,
+// see WooRequestQueue.Remove), otherwise the invalid requests are dropped, the others are sent
+// and the dropped ones are returned in a *WooPreflightError.
+func (w *WooConnection) SetPreflightValidation(enabled bool) {
+	w.preflight.Store(enabled)
+}
+
+// ValidateRequest checks a request without sending it: batch size, IDs of creates/updates/deletes,
+// duplicate SKUs inside a batch, enum values of products and required names; nil if the request is fine
+func ValidateRequest(r WooRequest) error {
+	var endpoint string
+	var problems []string
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	switch req := r.(type) {
+	case WooBatchPostRequest:
+		endpoint = req.Endpoint
+		if endpoint == "" {
+			add("endpoint is missing")
+		}
+		total := len(req.Create) + len(req.Update) + len(req.Delete)
+		if total > maxBatchSize {
+			add("batch of %d items exceeds the limit of %d", total, maxBatchSize)
+		}
+		resource := batchResource(endpoint)
+		skus := make(map[string]string)
+		check := func(section string, i int, item WooItem, create bool) {
+			name := fmt.Sprintf("%s[%d]", section, i)
+			if item == nil {
+				add("%s is empty", name)
+				return
+			}
+			if create && item.GetID() != 0 {
+				add("%s has an ID, creates must not have one", name)
+			}
+			if create == false && item.GetID() == 0 {
+				add("%s has no ID, updates need one", name)
+			}
+			for _, p := range itemProblems(resource, item, create) {
+				add("%s: %s", name, p)
+			}
+			if sku := itemSKU(item); sku != "" {
+				if other, ok := skus[sku]; ok {
+					add("%s has the same SKU %q as %s", name, sku, other)
+				} else {
+					skus[sku] = name
+				}
+			}
+		}
+		for i := range req.Create {
+			check("create", i, req.Create[i], true)
+		}
+		for i := range req.Update {
+			check("update", i, req.Update[i], false)
+		}
+		for i, id := range req.Delete {
+			if id <= 0 {
+				add("delete[%d] has no valid ID", i)
+			}
+		}
+
+	case *WooBatchPostRequest:
+		return ValidateRequest(*req)
+
+	case WooPostRequest:
+		endpoint = req.Endpoint
+		if endpoint == "" {
+			add("endpoint is missing")
+		}
+		if req.Payload == nil {
+			add("payload is missing")
+			break
+		}
+		// posting to the collection creates, posting to /<resource>/<id> updates
+		create := isCollectionEndpoint(endpoint)
+		if create && req.Payload.GetID() != 0 {
+			add("payload has an ID, creates must not have one")
+		}
+		for _, p := range itemProblems(batchResource(endpoint), req.Payload, create) {
+			add("payload: %s", p)
+		}
+
+	case *WooPostRequest:
+		return ValidateRequest(*req)
+
+	case WooDeleteRequest:
+		endpoint = req.Endpoint
+		if endpoint == "" {
+			add("endpoint is missing")
+		}
+	}
+
+	if len(problems) > 0 {
+		return &WooRequestValidationError{Endpoint: endpoint, Problems: problems}
+	}
+	return nil
+}
+
+// preflightRequests validates the requests, nil if all are fine
+func preflightRequests(requests []WooRequest) *WooPreflightError {
+	var perr *WooPreflightError
+	for i := range requests {
+		err := ValidateRequest(requests[i])
+		if err == nil {
+			continue
+		}
+		if perr == nil {
+			perr = &WooPreflightError{Invalid: make(map[int]*WooRequestValidationError), Rejected: make(map[int]WooRequest)}
+		}
+		perr.Invalid[i] = err.(*WooRequestValidationError)
+		perr.Rejected[i] = requests[i]
+	}
+	return perr
+}
+
+// itemProblems checks the enum values and required fields of a single item by its JSON representation
+// so WooProduct, WooProductPatch and WooRawItem are handled alike
+func itemProblems(resource string, item WooItem, create bool) []string {
+	b, err := json.Marshal(item)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	var fields map[string]interface{}
+	if json.Unmarshal(b, &fields) != nil {
+		return []string{"item is no JSON object"}
+	}
+
+	var problems []string
+	switch resource {
+	case "products", "variations":
+		for _, field := range sortedKeys(fields) {
+			allowed, ok := productEnumFields[field]
+			if ok == false || (resource == "variations" && (field == "type" || field == "catalog_visibility")) {
+				continue
+			}
+			value, _ := fields[field].(string)
+			if containsString(allowed, value) == false {
+				problems = append(problems, fmt.Sprintf("%s %q is not one of %s", field, fields[field], strings.Join(allowed[1:], ", ")))
+			}
+		}
+		if create && resource == "products" && emptyField(fields["name"]) {
+			problems = append(problems, "name is missing")
+		}
+	case "categories", "tags", "attributes", "terms":
+		if create && emptyField(fields["name"]) {
+			problems = append(problems, "name is missing")
+		}
+	}
+	return problems
+}
+
+// batchResource returns the kind of items an endpoint takes, e.g. "products" for /wp-json/wc/v3/products/batch
+// and "terms" for /wp-json/wc/v3/products/attributes/3/terms
+func batchResource(endpoint string) string {
+	parts := strings.Split(strings.Trim(endpointPath(endpoint), "/"), "/")
+	for i := len(parts) - 1; i >= 0; i-- {
+		switch parts[i] {
+		case "products", "variations", "categories", "tags", "attributes", "terms":
+			return parts[i]
+		}
+	}
+	return ""
+}
+
+// isCollectionEndpoint reports whether the endpoint ends with the resource name instead of an ID
+// false for resources batchResource does not know, e.g. orders
+func isCollectionEndpoint(endpoint string) bool {
+	resource := batchResource(endpoint)
+	if resource == "" {
+		return false
+	}
+	path := strings.Trim(endpointPath(endpoint), "/")
+	return strings.HasSuffix(path, "/"+resource)
+}
+
+func emptyField(v interface{}) bool {
+	s, ok := v.(string)
+	return v == nil || (ok && strings.TrimSpace(s) == "")
+}
+
+func containsString(list []string, s string) bool {
+	for i := range list {
+		if list[i] == s {
+			return true
+		}
+	}
+	return false
+}
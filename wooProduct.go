@@ -1,15 +1,17 @@
 package gowoocommerce
 
+import "encoding/json"
+
 // WooImage contains all the information on product images
 type WooImage struct {
 	ID int32 `json:"id,omitempty"`
 	//DateCreated     string `json:"date_created,omitempty"`
-	DateCreatedGMT string `json:"date_created_gmt,omitempty"`
+	DateCreatedGMT WooTime `json:"date_created_gmt,omitzero"`
 	//DateModified    string `json:"date_modified,omitempty"`
-	DateModifiedGMT string `json:"date_modified_gmt,omitempty"`
-	SRC             string `json:"src,omitempty"`
-	Name            string `json:"name,omitempty"`
-	Alt             string `json:"alt,omitempty"`
+	DateModifiedGMT WooTime `json:"date_modified_gmt,omitzero"`
+	SRC             string  `json:"src,omitempty"`
+	Name            string  `json:"name,omitempty"`
+	Alt             string  `json:"alt,omitempty"`
 }
 
 // WooTag interacts witht underlying category tree
@@ -28,8 +30,8 @@ type WooDimension struct {
 
 // WpmlPrice holds custom prices
 type WpmlPrice struct {
-	RegularPrice string `json:"regular_price,omitempty"`
-	SalePrice    string `json:"sale_price,omitempty"`
+	RegularPrice WooPrice `json:"regular_price,omitempty"`
+	SalePrice    WooPrice `json:"sale_price,omitempty"`
 }
 
 // WooProduct is the struct through which you interface with the WooCommerce backend
@@ -41,22 +43,22 @@ type WooProduct struct {
 	Slug      string `json:"slug,omitempty"`
 	Permalink string `json:"permalink,omitempty"` // read-only
 	//DateCreated       string                   `json:"date_created,omitempty"`      // read-only
-	DateCreatedGmt string `json:"date_created_gmt,omitempty"` // read-only
+	DateCreatedGmt WooTime `json:"date_created_gmt,omitzero"` // read-only
 	//DateModified      string                   `json:"date_modified,omitempty"`     // read-only
-	DateModifiedGmt   string `json:"date_modified_gmt,omitempty"` // read-only
-	Type              string `json:"type,omitempty"`
-	Status            string `json:"status,omitempty"`
-	Featured          bool   `json:"featured,omitempty"`
-	CatalogVisibility string `json:"catalog_visibility,omitempty"` // Options: visible, catalog, search and hidden. Default is visible.
-	Description       string `json:"description,omitempty"`
-	ShortDescription  string `json:"short_description,omitempty"`
+	DateModifiedGmt   WooTime `json:"date_modified_gmt,omitzero"` // read-only
+	Type              string  `json:"type,omitempty"`
+	Status            string  `json:"status,omitempty"`
+	Featured          bool    `json:"featured,omitempty"`
+	CatalogVisibility string  `json:"catalog_visibility,omitempty"` // Options: visible, catalog, search and hidden. Default is visible.
+	Description       string  `json:"description,omitempty"`
+	ShortDescription  string  `json:"short_description,omitempty"`
 	//Price             string                   `json:"price,omitempty"`         // read-only
-	RegularPrice      string `json:"regular_price,omitempty"`
-	SalePrice         string `json:"sale_price,omitempty"`
-	DateOnSaleFrom    string `json:"date_on_sale_from,omitempty"`
-	DateOnSaleFromGmt string `json:"date_on_sale_from_gmt,omitempty"`
-	DateOnSaleTo      string `json:"date_on_sale_to,omitempty"`
-	DateOnSaleToGmt   string `json:"date_on_sale_to_gmt,omitempty"`
+	RegularPrice      WooPrice `json:"regular_price,omitempty"`
+	SalePrice         WooPrice `json:"sale_price,omitempty"`
+	DateOnSaleFrom    WooTime  `json:"date_on_sale_from,omitzero"`
+	DateOnSaleFromGmt WooTime  `json:"date_on_sale_from_gmt,omitzero"`
+	DateOnSaleTo      WooTime  `json:"date_on_sale_to,omitzero"`
+	DateOnSaleToGmt   WooTime  `json:"date_on_sale_to_gmt,omitzero"`
 	//PriceHTML         string                   `json:"price_html,omitempty"`   // read-only
 	OnSale bool `json:"on_sale,omitempty"` // read-only
 	//Purchasable       bool                     `json:"purchasable,omitempty"`  // read-only
@@ -85,12 +87,14 @@ type WooProduct struct {
 	Variations        []string                 `json:"variations,omitempty"`
 	GroupedProducts   []int32                  `json:"grouped_products,omitempty"`
 	MenuOrder         int32                    `json:"menu_order,omitempty"`
-	MetaData          []map[string]interface{} `json:"meta_data,omitempty"`
+	MetaData          WooMetaData              `json:"meta_data,omitempty"`
 	Attributes        []WooAttribute           `json:"attributes,omitempty"`
 	Brands            []interface{}            `json:"brands,omitempty"`
 	Language          string                   `json:"language,omitempty"`
-	Lang              string                   `json:"lang,omitempty"`          // relates to the woocommerce multilingual package; otherwise: omit!
-	CustomPrices      map[string]WpmlPrice     `json:"custom_prices,omitempty"` // "custom_prices": {"EUR": {"regular_price": 100, "sale_price": 99}}
+	Lang              string                   `json:"lang,omitempty"`           // relates to the woocommerce multilingual package; otherwise: omit!
+	CustomPrices      map[string]WpmlPrice     `json:"custom_prices,omitempty"`  // "custom_prices": {"EUR": {"regular_price": 100, "sale_price": 99}}
+	TranslationOf     int32                    `json:"translation_of,omitempty"` // WPML: ID of the original when creating a translation
+	Translations      map[string]int32         `json:"translations,omitempty"`   // WPML: product IDs by language, read-only
 }
 
 // GetID implements WooItem
@@ -98,6 +102,17 @@ func (p WooProduct) GetID() int32 {
 	return int32(p.ID)
 }
 
+// MarshalJSON converts the *_gmt date fields to UTC, WooTime itself writes the wall clock of its location
+func (p WooProduct) MarshalJSON() ([]byte, error) {
+	type product WooProduct // without the MarshalJSON method
+	raw := product(p)
+	raw.DateCreatedGmt = gmtTime(raw.DateCreatedGmt)
+	raw.DateModifiedGmt = gmtTime(raw.DateModifiedGmt)
+	raw.DateOnSaleFromGmt = gmtTime(raw.DateOnSaleFromGmt)
+	raw.DateOnSaleToGmt = gmtTime(raw.DateOnSaleToGmt)
+	return json.Marshal(raw)
+}
+
 // AddImage adds an image +name ( +text to display when said image not available)
 func (p *WooProduct) AddImage(url string, name string, text string) {
 	p.Images = append(
@@ -0,0 +1,151 @@
+package gowoocommerce
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Product types
+const (
+	ProductTypeSimple   = "simple"
+	ProductTypeVariable = "variable"
+	ProductTypeGrouped  = "grouped"
+	ProductTypeExternal = "external"
+)
+
+// Allowed values of the product enum fields, "" leaves the shop's default
+var (
+	productTypes        = []string{"", ProductTypeSimple, ProductTypeVariable, ProductTypeGrouped, ProductTypeExternal}
+	productStatuses     = []string{"", "draft", "pending", "private", "publish", "future"}
+	stockStatuses       = []string{"", "instock", "outofstock", "onbackorder"}
+	catalogVisibilities = []string{"", "visible", "catalog", "search", "hidden"}
+	taxStatuses         = []string{"", "taxable", "shipping", "none"}
+)
+
+// WooValidationError lists everything wrong with a product
+type WooValidationError struct {
+	SKU      string
+	Problems []string
+}
+
+func (e *WooValidationError) Error() string {
+	return fmt.Sprintf("Invalid product %q: %s", e.SKU, strings.Join(e.Problems, "; "))
+}
+
+// NewSimpleProduct returns a simple product
+func NewSimpleProduct(sku, name string, regularPrice WooPrice) WooProduct {
+	return WooProduct{Type: ProductTypeSimple, SKU: sku, Name: name, RegularPrice: regularPrice}
+}
+
+// NewVariableProduct returns a variable product, copies of the attributes are marked as used for variations
+// prices and stock are set on the variations
+func NewVariableProduct(sku, name string, attributes ...WooAttribute) WooProduct {
+	attributes = append([]WooAttribute(nil), attributes...)
+	for i := range attributes {
+		attributes[i].Variation = true
+	}
+	return WooProduct{Type: ProductTypeVariable, SKU: sku, Name: name, Attributes: attributes}
+}
+
+// NewGroupedProduct returns a grouped product containing the given products
+func NewGroupedProduct(sku, name string, children ...int32) WooProduct {
+	return WooProduct{Type: ProductTypeGrouped, SKU: sku, Name: name, GroupedProducts: children}
+}
+
+// NewExternalProduct returns an external/affiliate product linking to url
+func NewExternalProduct(sku, name, url, buttonText string, regularPrice WooPrice) WooProduct {
+	return WooProduct{Type: ProductTypeExternal, SKU: sku, Name: name, ExternalURL: url, ButtonText: buttonText, RegularPrice: regularPrice}
+}
+
+// Validate checks the product before it is sent: enum values, fields that do not fit the type,
+// prices and the SKU. All problems are returned at once as *WooValidationError.
+// An empty type is checked as simple product, which is what WooCommerce creates without a type.
+func (p WooProduct) Validate() error {
+	var problems []string
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if strings.TrimSpace(p.SKU) == "" {
+		add("SKU is missing")
+	}
+
+	checkEnum := func(field, value string, allowed []string) {
+		for _, a := range allowed {
+			if value == a {
+				return
+			}
+		}
+		add("%s %q is not one of %s", field, value, strings.Join(allowed[1:], ", "))
+	}
+	checkEnum("type", p.Type, productTypes)
+	checkEnum("status", p.Status, productStatuses)
+	checkEnum("stock_status", p.StockStatus, stockStatuses)
+	checkEnum("catalog_visibility", p.CatalogVisibility, catalogVisibilities)
+	checkEnum("tax_status", p.TaxStatus, taxStatuses)
+
+	productType := p.Type
+	if productType == "" { // WooCommerce's default
+		productType = ProductTypeSimple
+	}
+	if productType != ProductTypeExternal {
+		if p.ExternalURL != "" {
+			add("external_url is only used by external products")
+		}
+		if p.ButtonText != "" {
+			add("button_text is only used by external products")
+		}
+	} else if p.ExternalURL == "" {
+		add("external products need an external_url")
+	}
+	if productType != ProductTypeGrouped && len(p.GroupedProducts) > 0 {
+		add("grouped_products is only used by grouped products")
+	}
+	if productType == ProductTypeGrouped || productType == ProductTypeVariable {
+		if p.RegularPrice.IsSet() || p.SalePrice.IsSet() {
+			add("%s products have no own price", productType)
+		}
+	}
+	if productType == ProductTypeVariable {
+		hasVariation := false
+		for _, a := range p.Attributes {
+			hasVariation = hasVariation || a.Variation
+		}
+		if hasVariation == false {
+			add("variable products need at least one attribute used for variations")
+		}
+	}
+
+	var regular, sale WooMoney
+	var err error
+	if p.RegularPrice.IsSet() {
+		regular, err = p.RegularPrice.Money()
+		if err != nil {
+			add("regular_price: %v", err)
+		} else if regular.Sign() < 0 {
+			add("regular_price is negative")
+		}
+	}
+	if p.SalePrice.IsSet() {
+		sale, err = p.SalePrice.Money()
+		if err != nil {
+			add("sale_price: %v", err)
+		} else if sale.Sign() < 0 {
+			add("sale_price is negative")
+		} else if p.RegularPrice.IsSet() && sale.Cmp(regular) > 0 {
+			add("sale_price %s is above regular_price %s", p.SalePrice, p.RegularPrice)
+		}
+	}
+
+	if p.DateOnSaleFromGmt.IsZero() == false && p.DateOnSaleToGmt.IsZero() == false && p.DateOnSaleToGmt.Before(p.DateOnSaleFromGmt.Time) {
+		add("sale ends before it starts")
+	}
+	if p.DateOnSaleFrom.IsZero() == false && p.DateOnSaleTo.IsZero() == false && p.DateOnSaleTo.Before(p.DateOnSaleFrom.Time) {
+		add("sale ends before it starts")
+	}
+
+	if len(problems) > 0 {
+		return &WooValidationError{SKU: p.SKU, Problems: problems}
+	}
+	return nil
+}
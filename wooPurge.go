@@ -0,0 +1,233 @@
+package gowoocommerce
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// WooPurgeOptions selects the products to be deleted by PurgeProductsWith and how they are deleted
+// at least one filter has to be set, or All to delete every product
+type WooPurgeOptions struct {
+	All            bool                    // required to delete without any filter
+	Category       int32                   // only products in this category ID
+	Status         string                  // only products with this status, e.g. "draft"
+	SKUPrefix      string                  // only products whose SKU starts with this prefix
+	ModifiedAfter  time.Time               // only products modified after this time
+	ModifiedBefore time.Time               // only products modified before this time
+	Match          func(p WooProduct) bool // optional additional filter, return true to delete
+
+	BackupPath   string                           // if set, the selected products are written to this JSON file before deletion
+	Confirm      func(products []WooProduct) bool // if set, deletion only starts if it returns true
+	Force        bool                             // true: delete permanently; false: move to trash
+	DeleteImages bool                             // also delete the media of the purged products and their variations nothing else uses
+	Verbose      bool
+}
+
+// WooPurgeReport lists what PurgeProductsWith deleted
+type WooPurgeReport struct {
+	Products []int32 // IDs of the deleted/trashed products
+	Failed   []int32 // IDs of the products that could not be deleted
+	Media    []int32 // IDs of the deleted media attachments
+}
+
+// PurgeProductsWith deletes the products selected by the options
+// Media are only deleted with Force, as trashed products still reference their images.
+// Deleting media uses /wp-json/wp/v2/media and therefore needs credentials WordPress accepts (e.g. an application password).
+func (w *WooConnection) PurgeProductsWith(opts WooPurgeOptions) (WooPurgeReport, error) {
+	var report WooPurgeReport
+
+	if w.initialized == false {
+		return report, errors.New("Please initialize with your credentials first. WooConnection.Init()")
+	}
+
+	q := NewProductQuery().OrderBy("id", "asc")
+	filtered := false
+	if opts.Category != 0 {
+		q.Category(opts.Category)
+		filtered = true
+	}
+	if opts.Status != "" {
+		q.Status(opts.Status)
+		filtered = true
+	}
+	if opts.ModifiedAfter.IsZero() == false {
+		q.ModifiedAfter(opts.ModifiedAfter)
+		filtered = true
+	}
+	if opts.ModifiedBefore.IsZero() == false {
+		q.ModifiedBefore(opts.ModifiedBefore)
+		filtered = true
+	}
+	if opts.SKUPrefix != "" || opts.Match != nil {
+		filtered = true
+	}
+	if filtered == false && opts.All == false {
+		return report, errors.New("Refusing to purge without a filter, set WooPurgeOptions.All to delete every product")
+	}
+	if opts.DeleteImages == true && opts.Force == false {
+		return report, errors.New("DeleteImages requires Force, trashed products still reference their images")
+	}
+
+	candidates, err := w.GetProducts(q)
+	if err != nil {
+		return report, err
+	}
+
+	var products []WooProduct
+	for i := range candidates {
+		if strings.HasPrefix(candidates[i].SKU, opts.SKUPrefix) == false {
+			continue
+		}
+		if opts.Match != nil && opts.Match(candidates[i]) == false {
+			continue
+		}
+		products = append(products, candidates[i])
+	}
+	if len(products) == 0 {
+		return report, nil
+	}
+
+	if opts.BackupPath != "" {
+		err = writeProductBackup(opts.BackupPath, products)
+		if err != nil {
+			return report, fmt.Errorf("Unable to write backup - %v", err)
+		}
+	}
+
+	if opts.Confirm != nil && opts.Confirm(products) == false {
+		return report, errors.New("Purge was not confirmed")
+	}
+
+	// the variations are deleted with their product, their images have to be known before
+	var variationImages map[int32][]int32
+	if opts.DeleteImages == true {
+		variationImages, err = w.variationImageIDs(products)
+		if err != nil {
+			return report, err
+		}
+	}
+
+	if opts.Force == true {
+		ids := make([]int, len(products))
+		for i := range products {
+			ids[i] = int(products[i].ID)
+		}
+		rsp, err := w.BatchDelete("/wp-json/wc/v3/products/batch?force=true", ids, opts.Verbose)
+		for i := range rsp.Delete {
+			if rsp.Delete[i].Error != nil {
+				report.Failed = append(report.Failed, rsp.Delete[i].ID)
+			} else {
+				report.Products = append(report.Products, rsp.Delete[i].ID)
+			}
+		}
+		if err != nil {
+			return report, err
+		}
+	} else {
+		requests := make([]WooRequest, len(products))
+		for i := range products {
+			requests[i] = WooDeleteRequest{
+				Endpoint: fmt.Sprintf("/wp-json/wc/v3/products/%d", products[i].ID),
+			}
+		}
+		_, err = w.executeRequests(requests, false, opts.Verbose, func(idx int, err error) {
+			if err != nil {
+				report.Failed = append(report.Failed, int32(products[idx].ID))
+			} else {
+				report.Products = append(report.Products, int32(products[idx].ID))
+			}
+		})
+		if err != nil {
+			return report, err
+		}
+		if len(report.Failed) > 0 {
+			return report, fmt.Errorf("%d of %d products could not be trashed", len(report.Failed), len(products))
+		}
+	}
+
+	if opts.DeleteImages == true {
+		report.Media, err = w.deleteOrphanedImages(products, variationImages, report.Products, opts.Verbose)
+		if err != nil {
+			return report, err
+		}
+	}
+
+	return report, nil
+}
+
+// variationImageIDs returns the image IDs of the variations of the variable products by product ID
+func (w *WooConnection) variationImageIDs(products []WooProduct) (map[int32][]int32, error) {
+	images := make(map[int32][]int32)
+	for i := range products {
+		if products[i].Type != "variable" {
+			continue
+		}
+		id := int32(products[i].ID)
+		endpoint := fmt.Sprintf("/wp-json/wc/v3/products/%d/variations?_fields=id,image", id)
+		for v, err := range Paginate[struct {
+			Image WooImage `json:"image"`
+		}](w, endpoint, defaultPageSize) {
+			if err != nil {
+				return nil, err
+			}
+			if v.Image.ID != 0 {
+				images[id] = append(images[id], v.Image.ID)
+			}
+		}
+	}
+	return images, nil
+}
+
+// deleteOrphanedImages deletes the images of the deleted products and their variations which are not used anymore, see FindOrphanedMedia
+func (w *WooConnection) deleteOrphanedImages(products []WooProduct, variationImages map[int32][]int32, deleted []int32, verbose bool) ([]int32, error) {
+	isDeleted := make(map[int32]bool, len(deleted))
+	for _, id := range deleted {
+		isDeleted[id] = true
+	}
+
+	candidates := make(map[int32]bool)
+	for i := range products {
+		if isDeleted[int32(products[i].ID)] == false {
+			continue
+		}
+		for _, img := range products[i].Images {
+			if img.ID != 0 {
+				candidates[img.ID] = true
+			}
+		}
+		for _, id := range variationImages[int32(products[i].ID)] {
+			candidates[id] = true
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	// images can be shared: keep everything a product (also in the trash), variation or category still uses
+	used, err := w.usedImageIDs()
+	if err != nil {
+		return nil, err
+	}
+	for id := range used {
+		delete(candidates, id)
+	}
+
+	var ids []int32
+	for id := range candidates {
+		ids = append(ids, id)
+	}
+	return w.DeleteMedia(ids, verbose)
+}
+
+// writeProductBackup writes the products as indented JSON array to path
+func writeProductBackup(path string, products []WooProduct) error {
+	b, err := json.MarshalIndent(products, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0644)
+}
@@ -0,0 +1,256 @@
+package gowoocommerce
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// wooTimeFormat is WooCommerce's zone-less ISO8601 format of date fields and date filters
+const wooTimeFormat = "2006-01-02T15:04:05"
+
+// WooProductQuery builds the filters for listing products, all values are URL encoded
+// https://woocommerce.github.io/woocommerce-rest-api-docs/#list-all-products
+// e.g.: NewProductQuery().Status("publish").StockStatus("instock").OrderBy("date", "desc")
+type WooProductQuery struct {
+	params url.Values
+}
+
+// NewProductQuery returns an empty product query
+func NewProductQuery() *WooProductQuery {
+	return &WooProductQuery{params: url.Values{}}
+}
+
+// Search limits results to those matching a string
+func (q *WooProductQuery) Search(s string) *WooProductQuery { return q.Set("search", s) }
+
+// SKU limits results to a specific SKU (comma separated for multiple SKUs)
+func (q *WooProductQuery) SKU(skus ...string) *WooProductQuery {
+	return q.Set("sku", strings.Join(skus, ","))
+}
+
+// Status limits results by status. Options: any, draft, pending, private and publish
+func (q *WooProductQuery) Status(s string) *WooProductQuery { return q.Set("status", s) }
+
+// Type limits results by type. Options: simple, grouped, external and variable
+func (q *WooProductQuery) Type(s string) *WooProductQuery { return q.Set("type", s) }
+
+// Category limits results to a category ID
+func (q *WooProductQuery) Category(id int32) *WooProductQuery { return q.Set("category", itoa32(id)) }
+
+// Tag limits results to a tag ID
+func (q *WooProductQuery) Tag(id int32) *WooProductQuery { return q.Set("tag", itoa32(id)) }
+
+// StockStatus limits results by stock status. Options: instock, outofstock, onbackorder
+func (q *WooProductQuery) StockStatus(s string) *WooProductQuery { return q.Set("stock_status", s) }
+
+// OnSale limits results to products (not) on sale
+func (q *WooProductQuery) OnSale(onSale bool) *WooProductQuery {
+	return q.Set("on_sale", strconv.FormatBool(onSale))
+}
+
+// Featured limits results to (not) featured products
+func (q *WooProductQuery) Featured(featured bool) *WooProductQuery {
+	return q.Set("featured", strconv.FormatBool(featured))
+}
+
+// MinPrice limits results to products with a price of at least the given value
+func (q *WooProductQuery) MinPrice(price string) *WooProductQuery { return q.Set("min_price", price) }
+
+// MaxPrice limits results to products with a price of at most the given value
+func (q *WooProductQuery) MaxPrice(price string) *WooProductQuery { return q.Set("max_price", price) }
+
+// ModifiedAfter limits results to products modified after the given time
+func (q *WooProductQuery) ModifiedAfter(t time.Time) *WooProductQuery {
+	q.Set("dates_are_gmt", "true")
+	return q.Set("modified_after", t.UTC().Format(wooTimeFormat))
+}
+
+// ModifiedBefore limits results to products modified before the given time
+func (q *WooProductQuery) ModifiedBefore(t time.Time) *WooProductQuery {
+	q.Set("dates_are_gmt", "true")
+	return q.Set("modified_before", t.UTC().Format(wooTimeFormat))
+}
+
+// After limits results to products published after the given time
+func (q *WooProductQuery) After(t time.Time) *WooProductQuery {
+	q.Set("dates_are_gmt", "true")
+	return q.Set("after", t.UTC().Format(wooTimeFormat))
+}
+
+// Before limits results to products published before the given time
+func (q *WooProductQuery) Before(t time.Time) *WooProductQuery {
+	q.Set("dates_are_gmt", "true")
+	return q.Set("before", t.UTC().Format(wooTimeFormat))
+}
+
+// Parent limits results to the children of the given parent IDs
+func (q *WooProductQuery) Parent(ids ...int32) *WooProductQuery { return q.Set("parent", joinIDs(ids)) }
+
+// Include limits results to the given IDs
+func (q *WooProductQuery) Include(ids ...int32) *WooProductQuery {
+	return q.Set("include", joinIDs(ids))
+}
+
+// Lang limits the results to a WPML language, "all" returns every language
+func (q *WooProductQuery) Lang(lang string) *WooProductQuery {
+	return q.Set("lang", lang)
+}
+
+// Exclude ensures results exclude the given IDs
+func (q *WooProductQuery) Exclude(ids ...int32) *WooProductQuery {
+	return q.Set("exclude", joinIDs(ids))
+}
+
+// OrderBy sorts by: date, id, include, title, slug, price, popularity and rating; order: asc or desc
+func (q *WooProductQuery) OrderBy(field, order string) *WooProductQuery {
+	q.Set("orderby", field)
+	return q.Set("order", order)
+}
+
+// Set sets any other query parameter, an empty value removes it
+func (q *WooProductQuery) Set(key, value string) *WooProductQuery {
+	if q.params == nil {
+		q.params = url.Values{}
+	}
+	if value == "" {
+		q.params.Del(key)
+	} else {
+		q.params.Set(key, value)
+	}
+	return q
+}
+
+// Values returns a copy of the encoded parameters
+func (q *WooProductQuery) Values() url.Values {
+	return copyValues(q.params)
+}
+
+// Endpoint returns the products endpoint including the encoded filters, e.g. to be used with Paginate
+func (q *WooProductQuery) Endpoint() string {
+	return withQuery("/wp-json/wc/v3/products", q.params)
+}
+
+// WooCategoryQuery builds the filters for listing product categories, all values are URL encoded
+// https://woocommerce.github.io/woocommerce-rest-api-docs/#list-all-product-categories
+type WooCategoryQuery struct {
+	params url.Values
+}
+
+// NewCategoryQuery returns an empty category query
+func NewCategoryQuery() *WooCategoryQuery {
+	return &WooCategoryQuery{params: url.Values{}}
+}
+
+// Search limits results to those matching a string
+func (q *WooCategoryQuery) Search(s string) *WooCategoryQuery { return q.Set("search", s) }
+
+// Slug limits results to categories with a specific slug
+func (q *WooCategoryQuery) Slug(s string) *WooCategoryQuery { return q.Set("slug", s) }
+
+// Parent limits results to the children of the given category ID, 0 returns the top level categories
+func (q *WooCategoryQuery) Parent(id int32) *WooCategoryQuery { return q.Set("parent", itoa32(id)) }
+
+// Product limits results to the categories assigned to a product ID
+func (q *WooCategoryQuery) Product(id int32) *WooCategoryQuery { return q.Set("product", itoa32(id)) }
+
+// Lang limits results to a WPML language, "all" returns every language
+func (q *WooCategoryQuery) Lang(lang string) *WooCategoryQuery { return q.Set("lang", lang) }
+
+// HideEmpty hides categories that are not assigned to any product
+func (q *WooCategoryQuery) HideEmpty(hide bool) *WooCategoryQuery {
+	return q.Set("hide_empty", strconv.FormatBool(hide))
+}
+
+// Include limits results to the given IDs
+func (q *WooCategoryQuery) Include(ids ...int32) *WooCategoryQuery {
+	return q.Set("include", joinIDs(ids))
+}
+
+// Exclude ensures results exclude the given IDs
+func (q *WooCategoryQuery) Exclude(ids ...int32) *WooCategoryQuery {
+	return q.Set("exclude", joinIDs(ids))
+}
+
+// OrderBy sorts by: id, include, name, slug, term_group, description and count; order: asc or desc
+func (q *WooCategoryQuery) OrderBy(field, order string) *WooCategoryQuery {
+	q.Set("orderby", field)
+	return q.Set("order", order)
+}
+
+// Set sets any other query parameter, an empty value removes it
+func (q *WooCategoryQuery) Set(key, value string) *WooCategoryQuery {
+	if q.params == nil {
+		q.params = url.Values{}
+	}
+	if value == "" {
+		q.params.Del(key)
+	} else {
+		q.params.Set(key, value)
+	}
+	return q
+}
+
+// Values returns a copy of the encoded parameters
+func (q *WooCategoryQuery) Values() url.Values {
+	return copyValues(q.params)
+}
+
+// Endpoint returns the categories endpoint including the encoded filters, e.g. to be used with Paginate
+func (q *WooCategoryQuery) Endpoint() string {
+	return withQuery("/wp-json/wc/v3/products/categories", q.params)
+}
+
+// GetProducts returns all products matching the query, nil returns all products
+func (w *WooConnection) GetProducts(q *WooProductQuery) ([]WooProduct, error) {
+	if w.initialized == false {
+		return nil, errors.New("Please initialize with your credentials first. WooConnection.Init()")
+	}
+	if q == nil {
+		q = NewProductQuery()
+	}
+
+	return CollectAll(Paginate[WooProduct](w, q.Endpoint(), defaultPageSize))
+}
+
+// GetCategories returns all categories matching the query, nil returns all categories
+func (w *WooConnection) GetCategories(q *WooCategoryQuery) ([]WooCategory, error) {
+	if w.initialized == false {
+		return nil, errors.New("Please initialize with your credentials first. WooConnection.Init()")
+	}
+	if q == nil {
+		q = NewCategoryQuery()
+	}
+
+	return CollectAll(Paginate[WooCategory](w, q.Endpoint(), defaultPageSize))
+}
+
+// withQuery appends the encoded parameters to an endpoint
+func withQuery(endpoint string, params url.Values) string {
+	if len(params) == 0 {
+		return endpoint
+	}
+	return endpoint + "?" + params.Encode()
+}
+
+func copyValues(v url.Values) url.Values {
+	c := url.Values{}
+	for key := range v {
+		c[key] = append([]string(nil), v[key]...)
+	}
+	return c
+}
+
+func joinIDs(ids []int32) string {
+	s := make([]string, len(ids))
+	for i := range ids {
+		s[i] = itoa32(ids[i])
+	}
+	return strings.Join(s, ",")
+}
+
+func itoa32(i int32) string {
+	return strconv.FormatInt(int64(i), 10)
+}
@@ -0,0 +1,326 @@
+package gowoocommerce
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// WooRequestQueue collects requests and sends them concurrently via its WooConnection
+// A queue is safe for concurrent use. Create one queue per job so independent jobs
+// sharing the same connection never execute each other's requests.
+type WooRequestQueue struct {
+	mu       sync.Mutex
+	conn     *WooConnection
+	requests []WooRequest
+	journal  *wooJournal // optional, see NewJournaledRequestQueue
+	ids      []int64     // journal IDs of the requests, only set with a journal
+}
+
+// queuedResponse carries the response of a queued request together with its position in the queue
+type queuedResponse struct {
+	idx  int
+	resp []byte
+	err  error
+}
+
+// NewRequestQueue returns an empty queue sending its requests through the connection
+func (w *WooConnection) NewRequestQueue() *WooRequestQueue {
+	return &WooRequestQueue{conn: w}
+}
+
+// Push appends a WooRequest to the queue to later be executed
+// with a journal the request is written to disk first; if that fails the request is not queued
+// and the error is returned (Execute keeps returning it, the journal is unusable afterwards)
+func (q *WooRequestQueue) Push(r WooRequest) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.journal != nil {
+		id, err := q.journal.add(r)
+		if err != nil {
+			return err
+		}
+		q.ids = append(q.ids, id)
+	}
+	q.requests = append(q.requests, r)
+	return nil
+}
+
+// Len returns the number of requests waiting in the queue
+func (q *WooRequestQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.requests)
+}
+
+// Execute sends all requests that were pushed before and returns an array of the raw responses as bytes
+// the responses are in the same order as the requests were pushed; the queue is empty afterwards
+// requests pushed while Execute is running are kept for the next call
+// if strict: returns the first error; else: finishes regardless of errors
+// with a journal every successful request is marked as completed, failed requests stay pending
+// and are put back on the queue, so the next Execute retries them like a restart would
+// with SetPreflightValidation the requests are validated first: if strict, invalid requests return a
+// *WooPreflightError before anything is sent and the queue is kept (see Remove); else they are dropped
+// with a nil response, marked done in the journal and returned in a *WooPreflightError after the others were sent
+func (q *WooRequestQueue) Execute(strict, verbose bool) ([][]byte, error) {
+	q.mu.Lock()
+	requests := q.requests
+	ids := q.ids
+	q.requests = nil
+	q.ids = nil
+	journal := q.journal
+	q.mu.Unlock()
+
+	if q.conn.preflight.Load() == false {
+		return q.send(requests, ids, journal, strict, verbose)
+	}
+
+	perr := preflightRequests(requests)
+	if perr == nil {
+		return q.send(requests, ids, journal, strict, verbose)
+	}
+	if strict == true {
+		q.requeue(requests, ids)
+		return nil, perr
+	}
+
+	// the invalid requests would never succeed, so they are removed from the journal as well
+	var valid []WooRequest
+	var validIDs []int64
+	var positions []int
+	for i := range requests {
+		if perr.Invalid[i] != nil {
+			fmt.Println(perr.Invalid[i])
+			if journal != nil {
+				err := journal.done(ids[i])
+				if err != nil {
+					q.requeue(requests, ids)
+					return nil, err
+				}
+			}
+			continue
+		}
+		valid = append(valid, requests[i])
+		if journal != nil {
+			validIDs = append(validIDs, ids[i])
+		}
+		positions = append(positions, i)
+	}
+
+	rsp, err := q.send(valid, validIDs, journal, strict, verbose)
+	rawResponse := make([][]byte, len(requests))
+	for i := range rsp {
+		rawResponse[positions[i]] = rsp[i]
+	}
+	if err != nil {
+		return rawResponse, err
+	}
+	return rawResponse, perr
+}
+
+// send executes the requests taken from the queue and updates the journal
+func (q *WooRequestQueue) send(requests []WooRequest, ids []int64, journal *wooJournal, strict, verbose bool) ([][]byte, error) {
+	if journal == nil {
+		return q.conn.executeRequests(requests, strict, verbose, nil)
+	}
+
+	err := journal.failure()
+	if err != nil {
+		q.requeue(requests, ids)
+		return nil, err
+	}
+
+	var journalErr error
+	var failed []int
+	rawResponse, err := q.conn.executeRequests(requests, strict, verbose, func(idx int, err error) {
+		if err != nil {
+			failed = append(failed, idx)
+			return
+		}
+		if journalErr != nil {
+			return
+		}
+		journalErr = journal.done(ids[idx])
+	})
+
+	// failed requests are still pending in the journal, keep them queued as well
+	sort.Ints(failed)
+	var retry []WooRequest
+	var retryIDs []int64
+	for _, idx := range failed {
+		retry = append(retry, requests[idx])
+		retryIDs = append(retryIDs, ids[idx])
+	}
+	q.requeue(retry, retryIDs)
+
+	if err != nil {
+		return rawResponse, err
+	}
+
+	return rawResponse, journalErr
+}
+
+// requeue puts requests taken by Execute back in front of the queue
+func (q *WooRequestQueue) requeue(requests []WooRequest, ids []int64) {
+	if len(requests) == 0 {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.requests = append(append([]WooRequest(nil), requests...), q.requests...)
+	if q.journal != nil {
+		q.ids = append(append([]int64(nil), ids...), q.ids...)
+	}
+}
+
+// Requests returns a copy of the requests waiting in the queue, in the order they will be sent
+func (q *WooRequestQueue) Requests() []WooRequest {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return append([]WooRequest(nil), q.requests...)
+}
+
+// Remove drops the requests at the given positions from the queue (and the journal) and returns them
+// e.g. q.Remove(perr.Positions()...) after a strict Execute failed the pre-flight validation
+func (q *WooRequestQueue) Remove(positions ...int) ([]WooRequest, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	drop := make(map[int]bool, len(positions))
+	for _, i := range positions {
+		if i < 0 || i >= len(q.requests) {
+			return nil, fmt.Errorf("No request at position %d", i)
+		}
+		drop[i] = true
+	}
+
+	var removed, kept []WooRequest
+	var keptIDs []int64
+	for i := range q.requests {
+		if drop[i] == false {
+			kept = append(kept, q.requests[i])
+			if q.journal != nil {
+				keptIDs = append(keptIDs, q.ids[i])
+			}
+			continue
+		}
+		if q.journal != nil {
+			err := q.journal.done(q.ids[i])
+			if err != nil {
+				return removed, err
+			}
+		}
+		removed = append(removed, q.requests[i])
+	}
+	q.requests = kept
+	q.ids = keptIDs
+	return removed, nil
+}
+
+// View returns the marshalled requests as they will be sent by Execute
+func (q *WooRequestQueue) View() ([][]byte, error) {
+	q.mu.Lock()
+	requests := append([]WooRequest(nil), q.requests...)
+	q.mu.Unlock()
+
+	output := make([][]byte, len(requests))
+	errs := make([]error, len(requests))
+
+	var wg sync.WaitGroup
+	for i := range requests {
+		wg.Add(1)
+		go func(it int) {
+			defer wg.Done()
+			output[it], errs[it] = json.Marshal(requests[it])
+		}(i)
+	}
+	wg.Wait()
+
+	var errorCounter int
+	for i := range errs {
+		if errs[i] != nil {
+			fmt.Println(errs[i])
+			errorCounter++
+		}
+	}
+	if errorCounter > 0 {
+		return output, fmt.Errorf("Encountered %d error in %d requests", errorCounter, len(requests))
+	}
+
+	return output, nil
+}
+
+// executeRequests sends the requests with up to maxConcurrentRequests workers and keeps the order of the responses
+// onDone (optional) is called for every finished request from a single goroutine
+func (w *WooConnection) executeRequests(requests []WooRequest, strict, verbose bool, onDone func(idx int, err error)) ([][]byte, error) {
+	var rawResponse [][]byte
+
+	if len(requests) == 0 {
+		return rawResponse, nil
+	}
+	var wg sync.WaitGroup
+
+	input := make(chan int, len(requests))
+	output := make(chan queuedResponse, len(requests))
+
+	workers := w.maxConcurrentRequests
+	if workers < 1 {
+		workers = 1
+	}
+
+	// Increment waitgroup counter and create go routines
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for idx := range input {
+				resp, err := requests[idx].Send(w)
+				output <- queuedResponse{idx: idx, resp: resp, err: err}
+			}
+		}()
+	}
+
+	// Producer: load up input channel with jobs
+	for idx := range requests {
+		input <- idx
+	}
+	if verbose == true {
+		fmt.Printf("%d scheduled \n", len(requests))
+	}
+
+	close(input)
+
+	rawResponse = make([][]byte, len(requests))
+	var firstErr error
+	for i := 0; i < len(requests); i++ {
+		res := <-output
+		rawResponse[res.idx] = res.resp
+		if onDone != nil {
+			onDone(res.idx, res.err)
+		}
+		if verbose == true {
+			progressBar(i+1, len(requests))
+		}
+
+		if res.err != nil {
+			fmt.Println(res.err)
+			if firstErr == nil {
+				firstErr = res.err
+			}
+		}
+	}
+
+	wg.Wait()
+
+	if strict == true && firstErr != nil {
+		return rawResponse, firstErr
+	}
+
+	return rawResponse, nil
+}
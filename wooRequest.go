@@ -41,6 +41,7 @@ func (p WooPostRequest) Send(w *WooConnection) ([]byte, error) {
 }
 
 // WooBatchPostRequest sends a payload of batch creations, updates and/or deletions
+// use ParseBatchResponse on the response to detect failures of single items
 type WooBatchPostRequest struct {
 	Endpoint string    `json:"-"`
 	Create   []WooItem `json:"create,omitempty"` // Create requests must not have IDs -the WC backend will generate them
@@ -99,3 +100,26 @@ func (g WooGetRequest) Send(w *WooConnection) ([]byte, error) {
 	}
 	return nil, fmt.Errorf("Error sending request - %v", err)
 }
+
+// WooDeleteRequest implements DELETE request via a WooConnection, e.g. "/wp-json/wc/v3/products/15?force=true"
+type WooDeleteRequest struct {
+	Endpoint string
+}
+
+// Send implements the WooRequest interface
+func (d WooDeleteRequest) Send(w *WooConnection) ([]byte, error) {
+	if w.initialized == false {
+		return nil, errors.New("Please initialize with your credentials first. WooConnection.Init()")
+	}
+
+	var err error
+	for i := 0; i < w.maxRetries; i++ {
+		var resp []byte
+		resp, err = w.Request("DELETE", d.Endpoint, nil)
+		if err == nil {
+			return resp, nil
+		}
+		fmt.Println(err)
+	}
+	return nil, fmt.Errorf("Error sending request - %v", err)
+}
@@ -0,0 +1,553 @@
+package gowoocommerce
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// Snapshot archive format: the first line is a WooSnapshotHeader, every following line one WooSnapshotRecord
+const (
+	SnapshotFormat  = "gowoocommerce-snapshot"
+	SnapshotVersion = 1
+)
+
+// Record types of a snapshot
+const (
+	SnapshotCategory  = "category"
+	SnapshotTag       = "tag"
+	SnapshotAttribute = "attribute"
+	SnapshotTerm      = "term" // Parent is the attribute ID
+	SnapshotProduct   = "product"
+	SnapshotVariation = "variation" // Parent is the product ID
+)
+
+// WooSnapshotHeader is the first line of a snapshot archive
+type WooSnapshotHeader struct {
+	Format  string    `json:"format"`
+	Version int       `json:"version"`
+	Created time.Time `json:"created"`
+	Source  string    `json:"source"`
+}
+
+// WooSnapshotRecord is one entity of a snapshot, the data is kept exactly as the shop returned it
+type WooSnapshotRecord struct {
+	Type   string          `json:"type"`
+	Parent int32           `json:"parent,omitempty"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// WooSnapshot is a complete snapshot archive read into memory
+type WooSnapshot struct {
+	Header  WooSnapshotHeader
+	Records []WooSnapshotRecord
+}
+
+// Products returns the products of the snapshot
+func (s WooSnapshot) Products() ([]WooProduct, error) {
+	var products []WooProduct
+	for _, rec := range s.Records {
+		if rec.Type != SnapshotProduct {
+			continue
+		}
+		var p WooProduct
+		err := json.Unmarshal(rec.Data, &p)
+		if err != nil {
+			return products, err
+		}
+		products = append(products, p)
+	}
+	return products, nil
+}
+
+// WooRestoreReport maps the IDs of the snapshot to the IDs in the target shop, per record type
+type WooRestoreReport struct {
+	IDMap    map[string]map[int32]int32
+	Existing map[string][]int32 // old IDs of the entities that already existed in the shop and were left untouched
+	Failed   []string           // description of every entity that could not be restored
+}
+
+// ExportSnapshot writes products, variations, categories, tags, attributes and terms as JSON lines archive
+func (w *WooConnection) ExportSnapshot(wr io.Writer, verbose bool) error {
+	if w.initialized == false {
+		return errors.New("Please initialize with your credentials first. WooConnection.Init()")
+	}
+
+	out := bufio.NewWriter(wr)
+	enc := json.NewEncoder(out)
+
+	err := enc.Encode(WooSnapshotHeader{
+		Format:  SnapshotFormat,
+		Version: SnapshotVersion,
+		Created: time.Now().UTC(),
+		Source:  w.credentials.domain,
+	})
+	if err != nil {
+		return err
+	}
+
+	write := func(recordType string, parent int32, endpoint string, paginated bool) ([]int32, error) {
+		var ids []int32
+		emit := func(raw json.RawMessage) error {
+			var head struct {
+				ID   int32  `json:"id"`
+				Type string `json:"type"`
+			}
+			json.Unmarshal(raw, &head)
+			ids = append(ids, head.ID)
+			if recordType == SnapshotProduct && head.Type != "variable" {
+				ids = ids[:len(ids)-1] // only variable products have variations
+			}
+			return enc.Encode(WooSnapshotRecord{Type: recordType, Parent: parent, Data: raw})
+		}
+
+		if paginated == false {
+			var items []json.RawMessage
+			err := w.getData(endpoint, &items)
+			if err != nil {
+				return nil, err
+			}
+			for _, raw := range items {
+				err = emit(raw)
+				if err != nil {
+					return nil, err
+				}
+			}
+			return ids, nil
+		}
+
+		for raw, err := range Paginate[json.RawMessage](w, endpoint, defaultPageSize) {
+			if err != nil {
+				return nil, err
+			}
+			err = emit(raw)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return ids, nil
+	}
+
+	_, err = write(SnapshotCategory, 0, "/wp-json/wc/v3/products/categories?orderby=id", true)
+	if err != nil {
+		return err
+	}
+	_, err = write(SnapshotTag, 0, "/wp-json/wc/v3/products/tags?orderby=id", true)
+	if err != nil {
+		return err
+	}
+	attributeIDs, err := write(SnapshotAttribute, 0, "/wp-json/wc/v3/products/attributes", false)
+	if err != nil {
+		return err
+	}
+	for _, id := range attributeIDs {
+		_, err = write(SnapshotTerm, id, fmt.Sprintf("/wp-json/wc/v3/products/attributes/%d/terms?orderby=id", id), true)
+		if err != nil {
+			return err
+		}
+	}
+	variableIDs, err := write(SnapshotProduct, 0, "/wp-json/wc/v3/products?status=any&orderby=id&order=asc", true)
+	if err != nil {
+		return err
+	}
+	for i, id := range variableIDs {
+		if verbose == true {
+			progressBar(i+1, len(variableIDs))
+		}
+		_, err = write(SnapshotVariation, id, fmt.Sprintf("/wp-json/wc/v3/products/%d/variations?orderby=id&order=asc", id), true)
+		if err != nil {
+			return err
+		}
+	}
+
+	return out.Flush()
+}
+
+// ReadSnapshot reads a snapshot archive written by ExportSnapshot
+func ReadSnapshot(r io.Reader) (WooSnapshot, error) {
+	var snapshot WooSnapshot
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+
+	if scanner.Scan() == false {
+		if scanner.Err() != nil {
+			return snapshot, scanner.Err()
+		}
+		return snapshot, errors.New("Empty snapshot")
+	}
+	err := json.Unmarshal(scanner.Bytes(), &snapshot.Header)
+	if err != nil || snapshot.Header.Format != SnapshotFormat {
+		return snapshot, errors.New("Not a snapshot archive")
+	}
+	if snapshot.Header.Version > SnapshotVersion {
+		return snapshot, fmt.Errorf("Unsupported snapshot version %d", snapshot.Header.Version)
+	}
+
+	for line := 2; scanner.Scan(); line++ {
+		var rec WooSnapshotRecord
+		err = json.Unmarshal(scanner.Bytes(), &rec)
+		if err != nil {
+			return snapshot, fmt.Errorf("Line %d: %v", line, err)
+		}
+		snapshot.Records = append(snapshot.Records, rec)
+	}
+
+	return snapshot, scanner.Err()
+}
+
+// RestoreSnapshot creates the entities of a snapshot archive in the shop of the connection
+// All references (category parents, upsells, cross-sells, grouped products, variation parents,
+// attribute and term IDs) are remapped to the new IDs. Entities that already exist in the target
+// shop (same slug or SKU) are mapped to the existing ones and left untouched (see report.Existing),
+// their references are not changed and no variations are added to them. Entities whose parent
+// category or global attribute could not be restored are reported in Failed, not created without it.
+// Images are sideloaded again from their URLs.
+func (w *WooConnection) RestoreSnapshot(r io.Reader, verbose bool) (WooRestoreReport, error) {
+	report := WooRestoreReport{IDMap: make(map[string]map[int32]int32), Existing: make(map[string][]int32)}
+
+	if w.initialized == false {
+		return report, errors.New("Please initialize with your credentials first. WooConnection.Init()")
+	}
+
+	snapshot, err := ReadSnapshot(r)
+	if err != nil {
+		return report, err
+	}
+
+	byType := make(map[string][]snapshotObject)
+	for _, rec := range snapshot.Records {
+		var data map[string]interface{}
+		err = json.Unmarshal(rec.Data, &data)
+		if err != nil {
+			return report, err
+		}
+		byType[rec.Type] = append(byType[rec.Type], snapshotObject{parent: rec.Parent, oldID: toInt32(data["id"]), data: data})
+	}
+	for _, t := range []string{SnapshotCategory, SnapshotTag, SnapshotAttribute, SnapshotTerm, SnapshotProduct, SnapshotVariation} {
+		report.IDMap[t] = make(map[int32]int32)
+	}
+
+	// categories: parents first
+	categories := byType[SnapshotCategory]
+	depth := categoryDepths(categories)
+	sort.SliceStable(categories, func(i, j int) bool { return depth[categories[i].oldID] < depth[categories[j].oldID] })
+	for start := 0; start < len(categories); {
+		end := start
+		for end < len(categories) && depth[categories[end].oldID] == depth[categories[start].oldID] {
+			end++
+		}
+		var level []snapshotObject
+		for _, c := range categories[start:end] {
+			stripFields(c.data, "id", "count", "_links")
+			stripImageIDs(c.data)
+			if parent := toInt32(c.data["parent"]); parent != 0 {
+				newParent, ok := report.IDMap[SnapshotCategory][parent]
+				if ok == false {
+					// restoring it at the top level would change the tree
+					report.Failed = append(report.Failed, fmt.Sprintf("%s %d: parent %d missing", SnapshotCategory, c.oldID, parent))
+					continue
+				}
+				c.data["parent"] = newParent
+			}
+			level = append(level, c)
+		}
+		err = w.restoreObjects(&report, SnapshotCategory, "/wp-json/wc/v3/products/categories/batch", level, verbose)
+		if err != nil {
+			return report, err
+		}
+		start = end
+	}
+
+	tags := byType[SnapshotTag]
+	for i := range tags {
+		stripFields(tags[i].data, "id", "count", "_links")
+	}
+	err = w.restoreObjects(&report, SnapshotTag, "/wp-json/wc/v3/products/tags/batch", tags, verbose)
+	if err != nil {
+		return report, err
+	}
+
+	attributes := byType[SnapshotAttribute]
+	for i := range attributes {
+		stripFields(attributes[i].data, "id", "_links")
+	}
+	err = w.restoreObjects(&report, SnapshotAttribute, "/wp-json/wc/v3/products/attributes/batch", attributes, verbose)
+	if err != nil {
+		return report, err
+	}
+
+	termsByAttribute := make(map[int32][]snapshotObject)
+	for _, term := range byType[SnapshotTerm] {
+		stripFields(term.data, "id", "count", "_links")
+		termsByAttribute[term.parent] = append(termsByAttribute[term.parent], term)
+	}
+	for attribute, terms := range termsByAttribute {
+		newAttribute, ok := report.IDMap[SnapshotAttribute][attribute]
+		if ok == false {
+			report.Failed = append(report.Failed, fmt.Sprintf("%d terms of attribute %d: attribute missing", len(terms), attribute))
+			continue
+		}
+		endpoint := fmt.Sprintf("/wp-json/wc/v3/products/attributes/%d/terms/batch", newAttribute)
+		err = w.restoreObjects(&report, SnapshotTerm, endpoint, terms, verbose)
+		if err != nil {
+			return report, err
+		}
+	}
+
+	// products: first without references to other products, then the references are set
+	var products []snapshotObject
+	var references []map[string]interface{}
+	for _, p := range byType[SnapshotProduct] {
+		refs := make(map[string]interface{})
+		for _, field := range []string{"upsell_ids", "cross_sell_ids", "grouped_products", "parent_id"} {
+			if v, ok := p.data[field]; ok {
+				refs[field] = v
+				delete(p.data, field)
+			}
+		}
+		err := w.prepareProduct(p.data, &report)
+		if err != nil {
+			report.Failed = append(report.Failed, fmt.Sprintf("%s %d: %v", SnapshotProduct, p.oldID, err))
+			continue
+		}
+		products = append(products, p)
+		references = append(references, refs)
+	}
+	err = w.restoreObjects(&report, SnapshotProduct, "/wp-json/wc/v3/products/batch", products, verbose)
+	if err != nil {
+		return report, err
+	}
+
+	// products that existed before are neither updated nor given variations
+	existing := make(map[int32]bool, len(report.Existing[SnapshotProduct]))
+	for _, id := range report.Existing[SnapshotProduct] {
+		existing[id] = true
+	}
+
+	var updates []WooItem
+	for i := range products {
+		newID, ok := report.IDMap[SnapshotProduct][products[i].oldID]
+		if ok == false || existing[products[i].oldID] {
+			continue
+		}
+		update := map[string]interface{}{"id": newID}
+		for field, v := range references[i] {
+			if list, ok := v.([]interface{}); ok {
+				if len(list) > 0 {
+					update[field] = remapIDs(list, report.IDMap[SnapshotProduct])
+				}
+			} else if parent := toInt32(v); parent != 0 {
+				newParent, ok := report.IDMap[SnapshotProduct][parent]
+				if ok == false {
+					report.Failed = append(report.Failed, fmt.Sprintf("%s of product %d: product %d missing", field, products[i].oldID, parent))
+					continue
+				}
+				update[field] = newParent
+			}
+		}
+		if len(update) > 1 {
+			updates = append(updates, WooRawItem(mustMarshal(update)))
+		}
+	}
+	if len(updates) > 0 {
+		rsp, err := w.BatchUpdate("/wp-json/wc/v3/products/batch", updates, verbose)
+		for _, f := range rsp.Failed() {
+			report.Failed = append(report.Failed, fmt.Sprintf("references of product %d: %v", f.ID, f.Error))
+		}
+		if err != nil && len(rsp.Update) == 0 {
+			return report, err
+		}
+	}
+
+	variationsByParent := make(map[int32][]snapshotObject)
+	for _, v := range byType[SnapshotVariation] {
+		err := w.prepareProduct(v.data, &report)
+		if err != nil {
+			report.Failed = append(report.Failed, fmt.Sprintf("%s %d: %v", SnapshotVariation, v.oldID, err))
+			continue
+		}
+		stripFields(v.data, "parent_id")
+		variationsByParent[v.parent] = append(variationsByParent[v.parent], v)
+	}
+	for parent, variations := range variationsByParent {
+		if existing[parent] {
+			continue
+		}
+		newParent, ok := report.IDMap[SnapshotProduct][parent]
+		if ok == false {
+			report.Failed = append(report.Failed, fmt.Sprintf("%d variations of product %d: product missing", len(variations), parent))
+			continue
+		}
+		endpoint := fmt.Sprintf("/wp-json/wc/v3/products/%d/variations/batch", newParent)
+		err = w.restoreObjects(&report, SnapshotVariation, endpoint, variations, verbose)
+		if err != nil {
+			return report, err
+		}
+	}
+
+	if len(report.Failed) > 0 {
+		return report, fmt.Errorf("%d entities could not be restored, first: %s", len(report.Failed), report.Failed[0])
+	}
+	return report, nil
+}
+
+// snapshotObject is a record of a snapshot decoded for the restore
+type snapshotObject struct {
+	parent int32
+	oldID  int32 // ID in the source shop
+	data   map[string]interface{}
+}
+
+// restoreObjects batch creates the objects and records their new IDs
+// objects that already exist (WooCommerce reports the existing resource_id) are mapped to the existing ones
+func (w *WooConnection) restoreObjects(report *WooRestoreReport, recordType, endpoint string, objects []snapshotObject, verbose bool) error {
+	if len(objects) == 0 {
+		return nil
+	}
+
+	items := make([]WooItem, len(objects))
+	for i := range objects {
+		delete(objects[i].data, "id")
+		items[i] = WooRawItem(mustMarshal(objects[i].data))
+	}
+
+	rsp, err := w.BatchCreate(endpoint, items, verbose)
+	if err != nil && len(rsp.Create) == 0 {
+		return err
+	}
+
+	for i := range rsp.Create {
+		res := rsp.Create[i]
+		switch {
+		case res.Error == nil:
+			report.IDMap[recordType][objects[i].oldID] = res.ID
+		case res.Error.Data.ResourceID != 0:
+			report.IDMap[recordType][objects[i].oldID] = res.Error.Data.ResourceID
+			report.Existing[recordType] = append(report.Existing[recordType], objects[i].oldID)
+		default:
+			report.Failed = append(report.Failed, fmt.Sprintf("%s %d: %v", recordType, objects[i].oldID, res.Error))
+		}
+	}
+	return nil
+}
+
+// prepareProduct removes read-only fields and remaps categories, tags and attributes of a product or variation
+// a global attribute that was not restored is an error, it would become a local attribute otherwise
+func (w *WooConnection) prepareProduct(data map[string]interface{}, report *WooRestoreReport) error {
+	stripFields(data, "permalink", "date_created", "date_created_gmt", "date_modified", "date_modified_gmt",
+		"price", "price_html", "on_sale", "purchasable", "total_sales", "backorders_allowed", "backordered",
+		"shipping_required", "shipping_taxable", "shipping_class_id", "average_rating", "rating_count",
+		"related_ids", "variations", "_links")
+	stripImageIDs(data)
+
+	for field, recordType := range map[string]string{"categories": SnapshotCategory, "tags": SnapshotTag} {
+		list, ok := data[field].([]interface{})
+		if ok == false {
+			continue
+		}
+		remapped := []interface{}{}
+		for _, entry := range list {
+			m, ok := entry.(map[string]interface{})
+			if ok == false {
+				continue
+			}
+			if newID, ok := report.IDMap[recordType][toInt32(m["id"])]; ok {
+				remapped = append(remapped, map[string]interface{}{"id": newID})
+			}
+		}
+		data[field] = remapped
+	}
+
+	for _, field := range []string{"attributes", "default_attributes"} {
+		list, ok := data[field].([]interface{})
+		if ok == false {
+			continue
+		}
+		for _, entry := range list {
+			m, ok := entry.(map[string]interface{})
+			if ok == false {
+				continue
+			}
+			if oldID := toInt32(m["id"]); oldID != 0 {
+				newID, ok := report.IDMap[SnapshotAttribute][oldID]
+				if ok == false {
+					return fmt.Errorf("attribute %d missing", oldID)
+				}
+				m["id"] = newID
+			}
+		}
+	}
+
+	if list, ok := data["meta_data"].([]interface{}); ok {
+		for _, entry := range list {
+			if m, ok := entry.(map[string]interface{}); ok {
+				delete(m, "id")
+			}
+		}
+	}
+	return nil
+}
+
+// categoryDepths returns the depth of every category in the tree (0 = top level)
+func categoryDepths(categories []snapshotObject) map[int32]int {
+	parents := make(map[int32]int32, len(categories))
+	for _, c := range categories {
+		parents[c.oldID] = toInt32(c.data["parent"])
+	}
+	depth := make(map[int32]int, len(categories))
+	for id := range parents {
+		d := 0
+		for p := parents[id]; p != 0 && d <= len(parents); p = parents[p] {
+			d++
+		}
+		depth[id] = d
+	}
+	return depth
+}
+
+// stripImageIDs drops the media IDs of the source shop, the images are sideloaded by their URL instead
+func stripImageIDs(data map[string]interface{}) {
+	if img, ok := data["image"].(map[string]interface{}); ok {
+		stripFields(img, "id", "date_created", "date_created_gmt", "date_modified", "date_modified_gmt")
+	}
+	if images, ok := data["images"].([]interface{}); ok {
+		for _, entry := range images {
+			if img, ok := entry.(map[string]interface{}); ok {
+				stripFields(img, "id", "date_created", "date_created_gmt", "date_modified", "date_modified_gmt")
+			}
+		}
+	}
+}
+
+func stripFields(data map[string]interface{}, fields ...string) {
+	for _, field := range fields {
+		delete(data, field)
+	}
+}
+
+func remapIDs(list []interface{}, idMap map[int32]int32) []int32 {
+	var ids []int32
+	for _, v := range list {
+		if newID, ok := idMap[toInt32(v)]; ok {
+			ids = append(ids, newID)
+		}
+	}
+	return ids
+}
+
+// toInt32 converts a decoded JSON number
+func toInt32(v interface{}) int32 {
+	switch n := v.(type) {
+	case float64:
+		return int32(n)
+	case json.Number:
+		i, _ := n.Int64()
+		return int32(i)
+	}
+	return 0
+}
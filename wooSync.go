@@ -0,0 +1,434 @@
+package gowoocommerce
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Actions for products that exist in the shop but not in the desired catalog
+const (
+	SyncMissingKeep   = ""       // leave them untouched
+	SyncMissingDraft  = "draft"  // set their status to draft
+	SyncMissingDelete = "delete" // delete them permanently
+)
+
+// readOnlyProductFields are never compared nor sent, WooCommerce computes them
+var readOnlyProductFields = map[string]bool{
+	"id":                true,
+	"permalink":         true,
+	"date_created_gmt":  true,
+	"date_modified_gmt": true,
+	"on_sale":           true,
+	"total_sales":       true,
+	"shipping_required": true,
+	"average_rating":    true,
+	"rating_count":      true,
+	"related_ids":       true,
+	"variations":        true,
+	"translations":      true,
+}
+
+// WooSyncOptions configures SyncProducts
+type WooSyncOptions struct {
+	MatchMetaKey string        // match by the value of this meta_data key instead of the SKU
+	Missing      string        // SyncMissingKeep, SyncMissingDraft or SyncMissingDelete
+	IgnoreFields []string      // additional JSON field names to ignore, e.g. "images"
+	Images       WooImageStore // if set, image sources are replaced by media IDs first, see ResolveImages
+	Verbose      bool
+}
+
+// WooFieldChange is the change of a single (JSON) field of a product
+type WooFieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old,omitempty"`
+	New   interface{} `json:"new,omitempty"`
+}
+
+// WooSyncChange lists the field changes of one product
+type WooSyncChange struct {
+	Key    string           `json:"key"` // SKU or meta value the product was matched by
+	ID     int32            `json:"id,omitempty"`
+	Fields []WooFieldChange `json:"fields,omitempty"`
+}
+
+// WooSyncReport lists what SyncProducts changed
+type WooSyncReport struct {
+	Created   []WooSyncChange      `json:"created,omitempty"`
+	Updated   []WooSyncChange      `json:"updated,omitempty"`
+	Drafted   []WooSyncChange      `json:"drafted,omitempty"`
+	Deleted   []WooSyncChange      `json:"deleted,omitempty"`
+	Unmatched []WooSyncChange      `json:"unmatched,omitempty"` // shop products without a key, left untouched
+	Unchanged int                  `json:"unchanged"`
+	Failed    []WooBatchItemResult `json:"-"`
+}
+
+// SyncProducts makes the shop's catalog match the desired products
+// Products are matched by SKU (or the meta key in opts), only fields set in the desired products are compared
+// and only the changed fields are sent. Read-only fields like permalink or total_sales are ignored.
+// Shop products without a key are reported as Unmatched and never drafted or deleted, duplicate keys in the shop are an error.
+// meta_data entries are matched by key, images by media ID: images given by SRC need opts.Images (or "images" in IgnoreFields).
+func (w *WooConnection) SyncProducts(desired []WooProduct, opts WooSyncOptions) (WooSyncReport, error) {
+	var report WooSyncReport
+
+	if w.initialized == false {
+		return report, errors.New("Please initialize with your credentials first. WooConnection.Init()")
+	}
+	if opts.Missing != SyncMissingKeep && opts.Missing != SyncMissingDraft && opts.Missing != SyncMissingDelete {
+		return report, fmt.Errorf("Unknown action for missing products %q", opts.Missing)
+	}
+
+	ignore := make(map[string]bool, len(readOnlyProductFields)+len(opts.IgnoreFields))
+	for field := range readOnlyProductFields {
+		ignore[field] = true
+	}
+	for _, field := range opts.IgnoreFields {
+		ignore[field] = true
+	}
+
+	if opts.Images != nil {
+		// resolve on copies, the caller's products stay untouched
+		desired = append([]WooProduct(nil), desired...)
+		for i := range desired {
+			desired[i].Images = append([]WooImage(nil), desired[i].Images...)
+		}
+		_, err := w.ResolveImages(desired, opts.Images, opts.Verbose)
+		if err != nil {
+			fmt.Println(err) // unresolved images are sideloaded by their URL as before
+		}
+	}
+
+	desiredByKey := make(map[string]int, len(desired))
+	for i := range desired {
+		key := syncKey(desired[i], opts.MatchMetaKey)
+		if key == "" {
+			return report, fmt.Errorf("Desired product %d (%s) has no key to match by", i, desired[i].Name)
+		}
+		if _, ok := desiredByKey[key]; ok {
+			return report, fmt.Errorf("Duplicate key %q in desired products", key)
+		}
+		desiredByKey[key] = i
+	}
+
+	current, err := w.GetProducts(NewProductQuery().Status("any").OrderBy("id", "asc"))
+	if err != nil {
+		return report, err
+	}
+	currentByKey := make(map[string]int, len(current))
+	for i := range current {
+		key := syncKey(current[i], opts.MatchMetaKey)
+		if key == "" {
+			continue
+		}
+		if j, ok := currentByKey[key]; ok {
+			return report, fmt.Errorf("Duplicate key %q in shop products %d and %d", key, current[j].ID, current[i].ID)
+		}
+		currentByKey[key] = i
+	}
+
+	var creates, updates []WooItem
+	var createChanges, updateChanges []WooSyncChange
+	for i := range desired {
+		key := syncKey(desired[i], opts.MatchMetaKey)
+		want, err := productFields(desired[i], ignore)
+		if err != nil {
+			return report, err
+		}
+
+		idx, exists := currentByKey[key]
+		if exists == false {
+			change := WooSyncChange{Key: key}
+			for _, field := range sortedKeys(want) {
+				change.Fields = append(change.Fields, WooFieldChange{Field: field, New: want[field]})
+			}
+			creates = append(creates, WooRawItem(mustMarshal(want)))
+			createChanges = append(createChanges, change)
+			continue
+		}
+
+		have, err := productFields(current[idx], nil)
+		if err != nil {
+			return report, err
+		}
+		change := WooSyncChange{Key: key, ID: int32(current[idx].ID)}
+		patch := map[string]interface{}{"id": current[idx].ID}
+		for _, field := range sortedKeys(want) {
+			var same bool
+			switch field {
+			case "meta_data":
+				same = containsMeta(have[field], want[field])
+			case "images":
+				same, err = containsImages(have[field], want[field])
+				if err != nil && opts.Images == nil {
+					return report, fmt.Errorf("Product %q: %v", key, err)
+				}
+			default:
+				same = containsJSON(have[field], want[field])
+			}
+			if same {
+				continue
+			}
+			change.Fields = append(change.Fields, WooFieldChange{Field: field, Old: have[field], New: want[field]})
+			patch[field] = want[field]
+		}
+		if len(change.Fields) == 0 {
+			report.Unchanged++
+			continue
+		}
+		updates = append(updates, WooRawItem(mustMarshal(patch)))
+		updateChanges = append(updateChanges, change)
+	}
+
+	var missing []WooSyncChange
+	for i := range current {
+		key := syncKey(current[i], opts.MatchMetaKey)
+		if key == "" {
+			// never draft or delete products that can not be matched at all
+			report.Unmatched = append(report.Unmatched, WooSyncChange{ID: int32(current[i].ID)})
+			continue
+		}
+		if _, ok := desiredByKey[key]; ok {
+			continue
+		}
+		if opts.Missing == SyncMissingDraft && current[i].Status == "draft" {
+			continue
+		}
+		change := WooSyncChange{Key: key, ID: int32(current[i].ID)}
+		if opts.Missing == SyncMissingDraft {
+			change.Fields = []WooFieldChange{{Field: "status", Old: current[i].Status, New: "draft"}}
+		}
+		missing = append(missing, change)
+	}
+
+	var errs []error
+	endpoint := "/wp-json/wc/v3/products/batch"
+
+	if len(creates) > 0 {
+		rsp, err := w.BatchCreate(endpoint, creates, opts.Verbose)
+		for i := range rsp.Create {
+			if rsp.Create[i].Error != nil {
+				report.Failed = append(report.Failed, rsp.Create[i])
+				continue
+			}
+			createChanges[i].ID = rsp.Create[i].ID
+			report.Created = append(report.Created, createChanges[i])
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(updates) > 0 {
+		rsp, err := w.BatchUpdate(endpoint, updates, opts.Verbose)
+		for i := range rsp.Update {
+			if rsp.Update[i].Error != nil {
+				report.Failed = append(report.Failed, rsp.Update[i])
+				continue
+			}
+			report.Updated = append(report.Updated, updateChanges[i])
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(missing) > 0 && opts.Missing == SyncMissingDraft {
+		drafts := make([]WooItem, len(missing))
+		for i := range missing {
+			drafts[i] = WooRawItem(mustMarshal(map[string]interface{}{"id": missing[i].ID, "status": "draft"}))
+		}
+		rsp, err := w.BatchUpdate(endpoint, drafts, opts.Verbose)
+		for i := range rsp.Update {
+			if rsp.Update[i].Error != nil {
+				report.Failed = append(report.Failed, rsp.Update[i])
+				continue
+			}
+			report.Drafted = append(report.Drafted, missing[i])
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(missing) > 0 && opts.Missing == SyncMissingDelete {
+		ids := make([]int, len(missing))
+		for i := range missing {
+			ids[i] = int(missing[i].ID)
+		}
+		rsp, err := w.BatchDelete(endpoint+"?force=true", ids, opts.Verbose)
+		for i := range rsp.Delete {
+			if rsp.Delete[i].Error != nil {
+				report.Failed = append(report.Failed, rsp.Delete[i])
+				continue
+			}
+			report.Deleted = append(report.Deleted, missing[i])
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return report, fmt.Errorf("Sync finished with %d failed items - %v", len(report.Failed), errs[0])
+	}
+
+	return report, nil
+}
+
+// syncKey returns the SKU or the value of the meta key a product is matched by
+func syncKey(p WooProduct, metaKey string) string {
+	if metaKey == "" {
+		return p.SKU
+	}
+	value, _ := p.MetaData.GetString(metaKey)
+	return value
+}
+
+// productFields returns the product as generic JSON object without the ignored fields
+func productFields(p WooProduct, ignore map[string]bool) (map[string]interface{}, error) {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	err = json.Unmarshal(b, &fields)
+	if err != nil {
+		return nil, err
+	}
+	for field := range fields {
+		if ignore[field] {
+			delete(fields, field)
+		}
+	}
+	pruneEmpty(fields)
+	return fields, nil
+}
+
+// pruneEmpty drops empty strings and objects from (nested) objects
+// types like WooDimension or WooCategory marshal unset fields (e.g. "name": ""), which must not count as set
+func pruneEmpty(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for key := range val {
+			val[key] = pruneEmpty(val[key])
+			if s, ok := val[key].(string); ok && s == "" {
+				delete(val, key)
+			} else if m, ok := val[key].(map[string]interface{}); ok && len(m) == 0 {
+				delete(val, key)
+			}
+		}
+		return val
+	case []interface{}:
+		for i := range val {
+			val[i] = pruneEmpty(val[i])
+		}
+		return val
+	}
+	return v
+}
+
+// containsJSON reports whether have contains everything set in want
+// objects only compare the keys of want (e.g. categories given by ID only), arrays compare element by element
+func containsJSON(have, want interface{}) bool {
+	switch wv := want.(type) {
+	case map[string]interface{}:
+		hv, ok := have.(map[string]interface{})
+		if ok == false {
+			return false
+		}
+		for key := range wv {
+			if containsJSON(hv[key], wv[key]) == false {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		hv, ok := have.([]interface{})
+		if ok == false || len(hv) != len(wv) {
+			return false
+		}
+		for i := range wv {
+			if containsJSON(hv[i], wv[i]) == false {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(have, want)
+}
+
+// containsMeta reports whether every wanted meta entry is in have, entries are matched by key
+// plugins (e.g. WPML, SEO) add entries of their own, their order does not matter either
+func containsMeta(have, want interface{}) bool {
+	hv, _ := have.([]interface{})
+	wv, _ := want.([]interface{})
+	for i := range wv {
+		entry, _ := wv[i].(map[string]interface{})
+		found := false
+		for j := range hv {
+			current, _ := hv[j].(map[string]interface{})
+			if current == nil || current["key"] != entry["key"] {
+				continue
+			}
+			found = true
+			for field := range entry {
+				if field != "id" && containsJSON(current[field], entry[field]) == false {
+					found = false
+				}
+			}
+			if found {
+				break
+			}
+		}
+		if found == false {
+			return false
+		}
+	}
+	return true
+}
+
+// containsImages compares images by their media ID (and the order), the shop rewrites the SRC of sideloaded images
+// images without ID can not be compared and are returned as error, they always count as changed
+func containsImages(have, want interface{}) (bool, error) {
+	hv, _ := have.([]interface{})
+	wv, _ := want.([]interface{})
+	for i := range wv {
+		img, _ := wv[i].(map[string]interface{})
+		if id, _ := img["id"].(float64); id == 0 {
+			return false, errors.New(`images without ID can not be compared, set WooSyncOptions.Images or ignore "images"`)
+		}
+	}
+	if len(hv) != len(wv) {
+		return false, nil
+	}
+	for i := range wv {
+		img := wv[i].(map[string]interface{})
+		current, _ := hv[i].(map[string]interface{})
+		for field := range img {
+			if field != "src" && containsJSON(current[field], img[field]) == false {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// mustMarshal marshals values that are known to be valid JSON (decoded JSON, plain maps)
+func mustMarshal(v interface{}) []byte {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
@@ -0,0 +1,98 @@
+package gowoocommerce
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// syncShop serves the given products and fails the test on any write request
+func syncShop(t *testing.T, products string) *WooConnection {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("unexpected %s %s", r.Method, r.URL)
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		rw.Header().Set("X-WP-TotalPages", "1")
+		fmt.Fprint(rw, products)
+	}))
+	t.Cleanup(srv.Close)
+
+	var w WooConnection
+	err := w.Init(srv.URL, "key", "secret", 10, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &w
+}
+
+func TestSyncProductsUnchanged(t *testing.T) {
+	w := syncShop(t, `[{
+		"id": 7, "sku": "A-1", "name": "Shirt", "status": "publish", "regular_price": "19.90",
+		"images": [{"id": 31, "src": "https://shop.example/wp-content/uploads/shirt.jpg", "name": "shirt", "alt": ""}],
+		"meta_data": [
+			{"id": 1, "key": "_wpml_media_featured", "value": "1"},
+			{"id": 2, "key": "erp_id", "value": "4711"},
+			{"id": 3, "key": "_yoast_wpseo_title", "value": "Shirt"}
+		]
+	}]`)
+
+	tests := []struct {
+		name string
+		opts WooSyncOptions
+		want WooProduct
+	}{
+		{"by SKU", WooSyncOptions{}, WooProduct{
+			SKU: "A-1", Name: "Shirt", RegularPrice: "19.90",
+			Images:   []WooImage{{ID: 31}},
+			MetaData: WooMetaData{{Key: "erp_id", Value: "4711"}},
+		}},
+		{"by meta key", WooSyncOptions{MatchMetaKey: "erp_id"}, WooProduct{
+			Name:     "Shirt",
+			MetaData: WooMetaData{{Key: "erp_id", Value: "4711"}},
+		}},
+	}
+	for _, tt := range tests {
+		report, err := w.SyncProducts([]WooProduct{tt.want}, tt.opts)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if report.Unchanged != 1 || len(report.Updated) != 0 || len(report.Created) != 0 {
+			t.Errorf("%s: got %+v, want 1 unchanged product", tt.name, report)
+		}
+	}
+}
+
+func TestSyncProductsImagesWithoutID(t *testing.T) {
+	w := syncShop(t, `[{"id": 7, "sku": "A-1", "images": [{"id": 31, "src": "https://shop.example/shirt.jpg"}]}]`)
+
+	_, err := w.SyncProducts([]WooProduct{{SKU: "A-1", Images: []WooImage{{SRC: "https://cdn.example/shirt.jpg"}}}}, WooSyncOptions{})
+	if err == nil {
+		t.Error("images given by SRC without an image store must be an error")
+	}
+}
+
+func TestContainsMeta(t *testing.T) {
+	have := []interface{}{
+		map[string]interface{}{"id": 1.0, "key": "a", "value": "1"},
+		map[string]interface{}{"id": 2.0, "key": "b", "value": "2"},
+	}
+	tests := []struct {
+		want []interface{}
+		same bool
+	}{
+		{nil, true},
+		{[]interface{}{map[string]interface{}{"key": "b", "value": "2"}}, true},
+		{[]interface{}{map[string]interface{}{"key": "b", "value": "2"}, map[string]interface{}{"key": "a", "value": "1"}}, true},
+		{[]interface{}{map[string]interface{}{"key": "b", "value": "3"}}, false},
+		{[]interface{}{map[string]interface{}{"key": "c", "value": "1"}}, false},
+	}
+	for i, tt := range tests {
+		if got := containsMeta(have, tt.want); got != tt.same {
+			t.Errorf("%d: containsMeta = %v, want %v", i, got, tt.same)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package gowoocommerce
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// wooTimeLayouts are accepted when parsing, the first one is WooCommerce's own format
+var wooTimeLayouts = []string{wooTimeFormat, "2006-01-02 15:04:05", time.RFC3339, "2006-01-02T15:04", "2006-01-02"}
+
+// WooTime is a date field in WooCommerce's zone-less format 2006-01-02T15:04:05, the zero value is "not set"
+// Values of *_gmt fields are UTC. Values of the local fields (e.g. date_on_sale_from) are in the shop's
+// timezone but are parsed as UTC wall clock too; use ShopTime to get the actual instant.
+type WooTime struct {
+	time.Time
+}
+
+// NewWooTime wraps t, use t.UTC() for *_gmt fields and t.In(shopLocation) for the local fields
+func NewWooTime(t time.Time) WooTime {
+	return WooTime{t}
+}
+
+// ParseWooTime parses the WooCommerce format, "2006-01-02 15:04:05", RFC 3339 and plain dates
+// an empty string returns the zero value
+func ParseWooTime(s string) (WooTime, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return WooTime{}, nil
+	}
+	for _, layout := range wooTimeLayouts {
+		t, err := time.Parse(layout, s)
+		if err == nil {
+			return WooTime{t}, nil
+		}
+	}
+	return WooTime{}, fmt.Errorf("Invalid date %q", s)
+}
+
+// String returns the time in WooCommerce's format (wall clock of its location), "" if not set
+func (t WooTime) String() string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Time.Format(wooTimeFormat)
+}
+
+// ShopTime interprets the wall clock of a local date field in the shop's timezone
+func (t WooTime) ShopTime(shop *time.Location) time.Time {
+	if t.IsZero() || shop == nil {
+		return t.Time
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), shop)
+}
+
+// MarshalJSON writes the zone-less format, null if not set
+func (t WooTime) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(t.String())
+}
+
+// UnmarshalJSON reads the zone-less format, "" and null give the zero value
+func (t *WooTime) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(bytes.TrimSpace(b), []byte("null")) {
+		*t = WooTime{}
+		return nil
+	}
+	var s string
+	err := json.Unmarshal(b, &s)
+	if err != nil {
+		return fmt.Errorf("Invalid date %s", b)
+	}
+	*t, err = ParseWooTime(s)
+	return err
+}
+
+// gmtTime returns t in UTC
+func gmtTime(t WooTime) WooTime {
+	if t.IsZero() {
+		return t
+	}
+	return WooTime{t.Time.UTC()}
+}
+
+// ScheduleSale sets the sale period, zero times leave that end open
+// the GMT fields are set, the local fields are cleared so they can not contradict them
+func (p *WooProduct) ScheduleSale(from, to time.Time) {
+	p.DateOnSaleFrom = WooTime{}
+	p.DateOnSaleTo = WooTime{}
+	p.DateOnSaleFromGmt = WooTime{}
+	p.DateOnSaleToGmt = WooTime{}
+	if from.IsZero() == false {
+		p.DateOnSaleFromGmt = WooTime{from.UTC()}
+	}
+	if to.IsZero() == false {
+		p.DateOnSaleToGmt = WooTime{to.UTC()}
+	}
+}
+
+// GetShopLocation returns the timezone configured in WordPress (Settings > General)
+// the local date fields (without _gmt) are in this timezone
+// /wp-json/wp/v2/settings needs an administrator's credentials WordPress accepts (e.g. an application password),
+// with WooCommerce API keys only the current UTC offset is known: it is derived from the newest product,
+// which is wrong for dates on the other side of a daylight saving change.
+func (w *WooConnection) GetShopLocation() (*time.Location, error) {
+	w.data.mu.Lock()
+	cached := w.data.location
+	w.data.mu.Unlock()
+	if cached != nil {
+		return cached, nil
+	}
+
+	var settings struct {
+		Timezone  string  `json:"timezone"`
+		GMTOffset float64 `json:"gmt_offset"`
+	}
+	err := w.getData("/wp-json/wp/v2/settings", &settings)
+	if err != nil {
+		loc, offsetErr := w.shopOffset()
+		if offsetErr != nil {
+			return nil, fmt.Errorf("Unable to read the timezone from /wp-json/wp/v2/settings (needs an administrator, e.g. an application password) - %v; %v", err, offsetErr)
+		}
+		w.data.mu.Lock()
+		w.data.location = loc
+		w.data.mu.Unlock()
+		return loc, nil
+	}
+
+	loc := time.UTC
+	if settings.Timezone != "" {
+		loc, err = time.LoadLocation(settings.Timezone)
+		if err != nil {
+			return nil, err
+		}
+	} else if settings.GMTOffset != 0 {
+		// manual offsets like "UTC+2" have no timezone name
+		loc = time.FixedZone(fmt.Sprintf("UTC%+g", settings.GMTOffset), int(settings.GMTOffset*3600))
+	}
+	w.data.mu.Lock()
+	w.data.location = loc
+	w.data.mu.Unlock()
+
+	return loc, nil
+}
+
+// shopOffset derives the shop's UTC offset from the local and GMT creation date of the newest product
+func (w *WooConnection) shopOffset() (*time.Location, error) {
+	var products []struct {
+		DateCreated    WooTime `json:"date_created"`
+		DateCreatedGmt WooTime `json:"date_created_gmt"`
+	}
+	err := w.getData("/wp-json/wc/v3/products?per_page=1&orderby=date&order=desc&status=any&_fields=date_created,date_created_gmt", &products)
+	if err != nil {
+		return nil, err
+	}
+	if len(products) == 0 || products[0].DateCreated.IsZero() || products[0].DateCreatedGmt.IsZero() {
+		return nil, errors.New("No product to derive the UTC offset from")
+	}
+	offset := int(products[0].DateCreated.Sub(products[0].DateCreatedGmt.Time).Round(time.Minute).Seconds())
+	return time.FixedZone(fmt.Sprintf("UTC%+g", float64(offset)/3600), offset), nil
+}
@@ -0,0 +1,403 @@
+package gowoocommerce
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// DefaultTranslationFields are the fields SyncTranslations copies from the original to its translations
+var DefaultTranslationFields = []string{
+	"sku", "regular_price", "sale_price", "date_on_sale_from_gmt", "date_on_sale_to_gmt",
+	"stock_quantity", "stock_status", "weight", "dimensions",
+}
+
+// WooTermTranslations maps term IDs to the IDs of their translations by language, e.g. [12]["de"] = 34
+type WooTermTranslations map[int32]map[string]int32
+
+// Translate returns the ID of the term in the given language, false if there is no translation
+func (t WooTermTranslations) Translate(id int32, lang string) (int32, bool) {
+	translated, ok := t[id][lang]
+	return translated, ok && translated != 0
+}
+
+// The helpers below need the WPML + WooCommerce Multilingual plugins,
+// which add the lang, translation_of and translations fields to the REST API.
+
+// CreateTranslation creates the translation of a product into lang
+// translated holds the translated texts; categories and tags not set are taken from the original
+// and, like the given ones, replaced by their translations. Type, SKU, prices, stock, weight,
+// dimensions, attributes and images not set are copied, the terms of global attributes are replaced
+// by their translations (given attributes are sent as they are). Variations are not created.
+func (w *WooConnection) CreateTranslation(originalID int32, lang string, translated WooProduct) (WooProduct, error) {
+	var created WooProduct
+
+	if w.initialized == false {
+		return created, errors.New("Please initialize with your credentials first. WooConnection.Init()")
+	}
+	if lang == "" {
+		return created, errors.New("No language given")
+	}
+
+	original, err := w.getProductAnyLang(originalID)
+	if err != nil {
+		return created, err
+	}
+	if lang == original.Lang {
+		return created, fmt.Errorf("Product %d already is in %s", originalID, lang)
+	}
+	if id, exists := original.Translations[lang]; exists {
+		return created, fmt.Errorf("Product %d already has a %s translation (%d)", originalID, lang, id)
+	}
+
+	translated.ID = 0
+	translated.Lang = lang
+	translated.TranslationOf = originalID
+	translated.Translations = nil
+	if translated.SKU == "" {
+		translated.SKU = original.SKU
+	}
+	if translated.Type == "" {
+		translated.Type = original.Type
+	}
+	if translated.RegularPrice == "" {
+		translated.RegularPrice = original.RegularPrice
+	}
+	if translated.SalePrice == "" {
+		translated.SalePrice = original.SalePrice
+	}
+	if translated.StockQuantity == 0 {
+		translated.StockQuantity = original.StockQuantity
+	}
+	if translated.StockStatus == "" {
+		translated.StockStatus = original.StockStatus
+	}
+	if translated.Weight == "" {
+		translated.Weight = original.Weight
+	}
+	if translated.Dimensions == (WooDimension{}) {
+		translated.Dimensions = original.Dimensions
+	}
+	if len(translated.Attributes) == 0 || len(translated.DefaultAttributes) == 0 {
+		attributes, defaults, err := w.translateAttributes(original.Attributes, original.DefaultAttributes, lang)
+		if err != nil {
+			return created, err
+		}
+		if len(translated.Attributes) == 0 {
+			translated.Attributes = attributes
+		}
+		if len(translated.DefaultAttributes) == 0 {
+			translated.DefaultAttributes = defaults
+		}
+	}
+	if len(translated.Categories) == 0 {
+		translated.Categories = original.Categories
+	}
+	if len(translated.Tags) == 0 {
+		translated.Tags = original.Tags
+	}
+	if len(translated.Images) == 0 {
+		for _, img := range original.Images {
+			translated.Images = append(translated.Images, WooImage{ID: img.ID})
+		}
+	}
+
+	translated.Categories, translated.Tags, err = w.TranslateTerms(translated.Categories, translated.Tags, lang)
+	if err != nil {
+		return created, err
+	}
+
+	resp, err := WooPostRequest{Endpoint: "/wp-json/wc/v3/products", Payload: translated}.Send(w)
+	if err != nil {
+		return created, err
+	}
+	err = json.Unmarshal(resp, &created)
+	return created, err
+}
+
+// GetTranslations returns the original and all translations of a product by language
+func (w *WooConnection) GetTranslations(productID int32) (map[string]WooProduct, error) {
+	if w.initialized == false {
+		return nil, errors.New("Please initialize with your credentials first. WooConnection.Init()")
+	}
+
+	product, err := w.getProductAnyLang(productID)
+	if err != nil {
+		return nil, err
+	}
+	if len(product.Translations) == 0 {
+		return map[string]WooProduct{product.Lang: product}, nil
+	}
+
+	var ids []int32
+	for _, id := range product.Translations {
+		ids = append(ids, id)
+	}
+	products, err := w.GetProducts(NewProductQuery().Include(ids...).Lang("all").Status("any"))
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int32]WooProduct, len(products))
+	for i := range products {
+		byID[products[i].GetID()] = products[i]
+	}
+	translations := make(map[string]WooProduct, len(product.Translations))
+	for lang, id := range product.Translations {
+		p, ok := byID[id]
+		if ok == false {
+			return translations, fmt.Errorf("Translation %d (%s) of product %d not found", id, lang, productID)
+		}
+		translations[lang] = p
+	}
+	return translations, nil
+}
+
+// SyncTranslations copies the given fields (DefaultTranslationFields if none) of the original product to all its translations
+// zero values are copied too, e.g. an empty sale price ends the sale in every language
+func (w *WooConnection) SyncTranslations(originalID int32, verbose bool, fields ...string) (WooBatchResponse, error) {
+	if len(fields) == 0 {
+		fields = DefaultTranslationFields
+	}
+
+	translations, err := w.GetTranslations(originalID)
+	if err != nil {
+		return WooBatchResponse{}, err
+	}
+
+	var original *WooProduct
+	for lang := range translations {
+		if translations[lang].GetID() == originalID {
+			p := translations[lang]
+			original = &p
+		}
+	}
+	if original == nil {
+		return WooBatchResponse{}, fmt.Errorf("Product %d not found among its translations", originalID)
+	}
+
+	langs := make([]string, 0, len(translations))
+	for lang := range translations {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+
+	var patches []*WooProductPatch
+	for _, lang := range langs {
+		if translations[lang].GetID() == originalID {
+			continue
+		}
+		patch, err := PatchFromProduct(*original, fields...)
+		if err != nil {
+			return WooBatchResponse{}, err
+		}
+		patch.ID = translations[lang].GetID()
+		patches = append(patches, patch)
+	}
+	if len(patches) == 0 {
+		return WooBatchResponse{}, nil
+	}
+
+	return w.PatchProducts(patches, verbose)
+}
+
+// TranslateTerms replaces categories and tags by their translations into lang
+// terms without translation are returned as error, the lists contain the IDs only
+// the translations are loaded once per connection, see GetTermTranslations
+func (w *WooConnection) TranslateTerms(categories []WooCategory, tags []WooTag, lang string) ([]WooCategory, []WooTag, error) {
+	var missing []string
+
+	var translatedCategories []WooCategory
+	if len(categories) > 0 {
+		translations, err := w.GetTermTranslations("/wp-json/wc/v3/products/categories")
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, c := range categories {
+			id, ok := translations.Translate(c.ID, lang)
+			if ok == false {
+				missing = append(missing, fmt.Sprintf("category %d", c.ID))
+				continue
+			}
+			translatedCategories = append(translatedCategories, WooCategory{ID: id})
+		}
+	}
+
+	var translatedTags []WooTag
+	if len(tags) > 0 {
+		translations, err := w.GetTermTranslations("/wp-json/wc/v3/products/tags")
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, t := range tags {
+			id, ok := translations.Translate(t.ID, lang)
+			if ok == false {
+				missing = append(missing, fmt.Sprintf("tag %d", t.ID))
+				continue
+			}
+			translatedTags = append(translatedTags, WooTag{ID: id})
+		}
+	}
+
+	if len(missing) > 0 {
+		return translatedCategories, translatedTags, fmt.Errorf("No %s translation for %v", lang, missing)
+	}
+	return translatedCategories, translatedTags, nil
+}
+
+// translateAttributes returns copies of the attributes with the terms of global attributes translated into lang
+// local attributes (ID 0) have no terms, their options are copied as they are
+func (w *WooConnection) translateAttributes(attributes []WooAttribute, defaults []map[string]interface{}, lang string) ([]WooAttribute, []map[string]interface{}, error) {
+	var missing []string
+	termNames := make(map[int32]map[string]string)
+	translate := func(attributeID int32, option string) (string, error) {
+		if _, ok := termNames[attributeID]; ok == false {
+			names, err := w.attributeTermNames(attributeID, lang)
+			if err != nil {
+				return "", err
+			}
+			termNames[attributeID] = names
+		}
+		name, ok := termNames[attributeID][option]
+		if ok == false {
+			missing = append(missing, fmt.Sprintf("attribute %d term %q", attributeID, option))
+		}
+		return name, nil
+	}
+
+	var translated []WooAttribute
+	for _, a := range attributes {
+		if a.ID != 0 {
+			options := make([]string, len(a.Options))
+			for i := range a.Options {
+				var err error
+				options[i], err = translate(a.ID, a.Options[i])
+				if err != nil {
+					return nil, nil, err
+				}
+			}
+			a.Options = options
+		}
+		translated = append(translated, a)
+	}
+
+	var translatedDefaults []map[string]interface{}
+	for _, d := range defaults {
+		copied := make(map[string]interface{}, len(d))
+		for key, value := range d {
+			copied[key] = value
+		}
+		id := toInt32(d["id"])
+		option, _ := d["option"].(string)
+		if id != 0 && option != "" {
+			var err error
+			copied["option"], err = translate(id, option)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		translatedDefaults = append(translatedDefaults, copied)
+	}
+
+	if len(missing) > 0 {
+		return translated, translatedDefaults, fmt.Errorf("No %s translation for %v", lang, missing)
+	}
+	return translated, translatedDefaults, nil
+}
+
+// attributeTermNames maps the term names of a global attribute to the names of their translations into lang
+func (w *WooConnection) attributeTermNames(attributeID int32, lang string) (map[string]string, error) {
+	type term struct {
+		ID           int32            `json:"id"`
+		Name         string           `json:"name"`
+		Translations map[string]int32 `json:"translations"`
+	}
+
+	endpoint := fmt.Sprintf("/wp-json/wc/v3/products/attributes/%d/terms?lang=all&_fields=id,name,translations", attributeID)
+	var terms []term
+	names := make(map[int32]string)
+	for t, err := range Paginate[term](w, endpoint, defaultPageSize) {
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, t)
+		names[t.ID] = t.Name
+	}
+
+	translated := make(map[string]string, len(terms))
+	for _, t := range terms {
+		if id, ok := t.Translations[lang]; ok && id != t.ID && names[id] != "" {
+			translated[t.Name] = names[id]
+		}
+	}
+	return translated, nil
+}
+
+// GetTermTranslations loads the translations of all terms of a taxonomy endpoint,
+// e.g. "/wp-json/wc/v3/products/categories" or "/wp-json/wc/v3/products/tags"
+// The result is a copy of the data cached on the connection, call ClearDataCache after creating translated terms.
+func (w *WooConnection) GetTermTranslations(endpoint string) (WooTermTranslations, error) {
+	w.data.mu.Lock()
+	cached, ok := w.data.terms[endpoint]
+	w.data.mu.Unlock()
+	if ok {
+		return copyTermTranslations(cached), nil
+	}
+
+	type term struct {
+		ID           int32            `json:"id"`
+		Lang         string           `json:"lang"`
+		Translations map[string]int32 `json:"translations"`
+	}
+
+	translations := make(WooTermTranslations)
+	query := setQueryParam(endpoint, "lang", "all")
+	query = setQueryParam(query, "_fields", "id,lang,translations")
+	for t, err := range Paginate[term](w, query, defaultPageSize) {
+		if err != nil {
+			return nil, err
+		}
+		byLang := make(map[string]int32, len(t.Translations)+1)
+		for lang, id := range t.Translations {
+			byLang[lang] = id
+		}
+		if t.Lang != "" {
+			byLang[t.Lang] = t.ID
+		}
+		translations[t.ID] = byLang
+	}
+
+	w.data.mu.Lock()
+	if w.data.terms == nil {
+		w.data.terms = make(map[string]WooTermTranslations)
+	}
+	w.data.terms[endpoint] = translations
+	w.data.mu.Unlock()
+	return copyTermTranslations(translations), nil
+}
+
+// copyTermTranslations copies the translations including the maps by language
+func copyTermTranslations(translations WooTermTranslations) WooTermTranslations {
+	copied := make(WooTermTranslations, len(translations))
+	for id, byLang := range translations {
+		langs := make(map[string]int32, len(byLang))
+		for lang, translated := range byLang {
+			langs[lang] = translated
+		}
+		copied[id] = langs
+	}
+	return copied
+}
+
+// getProductAnyLang loads a single product regardless of its language
+func (w *WooConnection) getProductAnyLang(id int32) (WooProduct, error) {
+	products, err := w.GetProducts(NewProductQuery().Include(id).Lang("all").Status("any"))
+	if err != nil {
+		return WooProduct{}, err
+	}
+	if len(products) == 0 {
+		return WooProduct{}, fmt.Errorf("Product %d not found", id)
+	}
+	return products[0], nil
+}